@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// Package internal: BIP-39 entropy/mnemonic/checksum helpers.
+//
+// For an N-word phrase, entropy length in bits is ENT = N*32/33 (128/160/192/
+// 224/256 for N = 12/15/18/21/24), and checksum length is CS = ENT/32. Each
+// word contributes an 11-bit big-endian index; the concatenation of all
+// indices is an ENT+CS bit string whose last CS bits must equal the top CS
+// bits of sha256(entropyBytes).
+
+// bip39EntropyBitsForWordCount returns ENT for a supported word count, or
+// (0, false) if wordCount isn't one of 12/15/18/21/24.
+func bip39EntropyBitsForWordCount(wordCount int) (int, bool) {
+	switch wordCount {
+	case 12:
+		return 128, true
+	case 15:
+		return 160, true
+	case 18:
+		return 192, true
+	case 21:
+		return 224, true
+	case 24:
+		return 256, true
+	default:
+		return 0, false
+	}
+}
+
+// MnemonicToEntropy converts an English BIP-39 mnemonic to its entropy bytes,
+// verifying the checksum along the way. Use MnemonicToEntropyWithList for a
+// non-English wordlist, and ValidateMnemonic first if you only need a
+// yes/no answer.
+func MnemonicToEntropy(words []string) ([]byte, error) {
+	return MnemonicToEntropyWithList(words, WordsBIP39EN)
+}
+
+// MnemonicToEntropyWithList is MnemonicToEntropy generalized to any 2048-word
+// BIP-39 wordlist, so multi-language mnemonics can be decoded the same way
+// as English ones. Word lookup is NormalizeWord-normalized (NFKD, trimmed,
+// lowercased) so it's insensitive to composed/decomposed accents.
+func MnemonicToEntropyWithList(words []string, wordsList []string) ([]byte, error) {
+	if len(wordsList) != Total {
+		return nil, fmt.Errorf("wordsList length must be %d, got %d", Total, len(wordsList))
+	}
+	entBits, ok := bip39EntropyBitsForWordCount(len(words))
+	if !ok {
+		return nil, fmt.Errorf("unsupported mnemonic length %d (want 12, 15, 18, 21, or 24 words)", len(words))
+	}
+	csBits := entBits / 32
+
+	bipIndex := make(map[string]int, len(wordsList))
+	for i, w := range wordsList {
+		bipIndex[NormalizeWord(w)] = i
+	}
+
+	bits := newBitWriter(entBits + csBits)
+	for _, w := range words {
+		idx, ok := bipIndex[NormalizeWord(w)]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a BIP-39 word", w)
+		}
+		bits.writeBits(uint32(idx), 11)
+	}
+
+	entropy := bits.bytesForBits(entBits)
+	claimed := bits.bitsAfter(entBits, csBits)
+
+	sum := sha256.Sum256(entropy)
+	actual := bitsFromBytes(sum[:], csBits)
+	if claimed != actual {
+		return nil, fmt.Errorf("invalid mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+// ValidateMnemonic verifies that words form a checksum-valid BIP-39 mnemonic.
+func ValidateMnemonic(words []string) error {
+	_, err := MnemonicToEntropy(words)
+	return err
+}
+
+// EntropyToMnemonic converts raw entropy (16/20/24/28/32 bytes) into its
+// checksum-valid English BIP-39 mnemonic. Use EntropyToMnemonicWithList for a
+// non-English wordlist.
+func EntropyToMnemonic(ent []byte) ([]string, error) {
+	return EntropyToMnemonicWithList(ent, WordsBIP39EN)
+}
+
+// EntropyToMnemonicWithList is EntropyToMnemonic generalized to any
+// 2048-word BIP-39 wordlist.
+func EntropyToMnemonicWithList(ent []byte, wordsList []string) ([]string, error) {
+	if len(wordsList) != Total {
+		return nil, fmt.Errorf("wordsList length must be %d, got %d", Total, len(wordsList))
+	}
+	entBitsLen := len(ent) * 8
+	wordCount, ok := bip39WordCountForEntropyBits(entBitsLen)
+	if !ok {
+		return nil, fmt.Errorf("unsupported entropy length %d bytes (want 16, 20, 24, 28, or 32)", len(ent))
+	}
+	csBits := entBitsLen / 32
+
+	sum := sha256.Sum256(ent)
+	checksum := bitsFromBytes(sum[:], csBits)
+
+	bits := newBitWriter(entBitsLen + csBits)
+	for _, b := range ent {
+		bits.writeBits(uint32(b), 8)
+	}
+	bits.writeBits(checksum, csBits)
+
+	words := make([]string, wordCount)
+	for i := 0; i < wordCount; i++ {
+		idx := bits.readBits(i*11, 11)
+		words[i] = wordsList[idx]
+	}
+	return words, nil
+}
+
+func bip39WordCountForEntropyBits(bits int) (int, bool) {
+	switch bits {
+	case 128:
+		return 12, true
+	case 160:
+		return 15, true
+	case 192:
+		return 18, true
+	case 224:
+		return 21, true
+	case 256:
+		return 24, true
+	default:
+		return 0, false
+	}
+}
+
+// bitWriter accumulates bits MSB-first into a byte buffer sized to hold
+// exactly totalBits bits, then exposes them either as whole bytes or as a
+// packed integer for checksum comparison (CS is always <= 8 in BIP-39).
+type bitWriter struct {
+	buf []byte
+	pos int // next bit offset to write, MSB-first within each byte
+}
+
+func newBitWriter(totalBits int) *bitWriter {
+	return &bitWriter{buf: make([]byte, (totalBits+7)/8)}
+}
+
+func (w *bitWriter) writeBits(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		bit := (v >> uint(i)) & 1
+		byteIdx := w.pos / 8
+		bitIdx := 7 - uint(w.pos%8)
+		if bit == 1 {
+			w.buf[byteIdx] |= 1 << bitIdx
+		}
+		w.pos++
+	}
+}
+
+func (w *bitWriter) readBits(offset, n int) uint32 {
+	var v uint32
+	for i := 0; i < n; i++ {
+		byteIdx := (offset + i) / 8
+		bitIdx := 7 - uint((offset+i)%8)
+		bit := (w.buf[byteIdx] >> bitIdx) & 1
+		v = (v << 1) | uint32(bit)
+	}
+	return v
+}
+
+// bytesForBits returns the first n bits of the buffer as whole bytes (n must
+// be a multiple of 8).
+func (w *bitWriter) bytesForBits(n int) []byte {
+	return append([]byte(nil), w.buf[:n/8]...)
+}
+
+// bitsAfter returns the n bits starting at bit offset start, packed into the
+// low bits of a uint32 (n <= 32).
+func (w *bitWriter) bitsAfter(start, n int) uint32 {
+	return w.readBits(start, n)
+}
+
+// bitsFromBytes returns the top n bits of data, packed into the low bits of a
+// uint32 (n <= 32).
+func bitsFromBytes(data []byte, n int) uint32 {
+	var v uint32
+	for i := 0; i < n; i++ {
+		byteIdx := i / 8
+		bitIdx := 7 - uint(i%8)
+		bit := (data[byteIdx] >> bitIdx) & 1
+		v = (v << 1) | uint32(bit)
+	}
+	return v
+}
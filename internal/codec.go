@@ -5,7 +5,9 @@ import (
 	"strings"
 )
 
-// EncodeWords encodes each input word into a 4-glyph token using the 4×7 unique scheme.
+// EncodeWords encodes each input word into a 4-glyph token using the 4×7 unique
+// scheme and the default SHAKE256 permutation RNG. Use EncodeWordsWithPolicy to
+// honor a KeyPolicy.RNG selection (e.g. "sha256ctr" for older permutations).
 //
 // Parameters:
 //   - input: the list of words to encode
@@ -24,15 +26,21 @@ import (
 //   - slice of encoded 4‑glyph tokens (same order as input)
 //   - error if any word is not present in index or invalid parameters were provided
 func EncodeWords(input []string, index map[string]int, wordsList []string, key string) ([]string, error) {
+	return EncodeWordsWithPolicy(input, index, wordsList, key, DefaultKeyPolicy())
+}
+
+// EncodeWordsWithPolicy is EncodeWords, but derives the permutation via
+// DeriveWithPolicy so policy.RNG selects the XOF (see perm.go).
+func EncodeWordsWithPolicy(input []string, index map[string]int, wordsList []string, key string, policy KeyPolicy) ([]string, error) {
 	if len(wordsList) != Total {
 		return nil, fmt.Errorf("wordsList length must be %d, got %d", Total, len(wordsList))
 	}
 
-	_, inv := Derive(len(wordsList), key)
+	_, inv := DeriveWithPolicy(len(wordsList), key, policy)
 
 	out := make([]string, 0, len(input))
 	for _, w := range input {
-		lw := strings.ToLower(strings.TrimSpace(w))
+		lw := NormalizeWord(w)
 		if lw == "" {
 			continue
 		}
@@ -58,7 +66,9 @@ func EncodeWords(input []string, index map[string]int, wordsList []string, key s
 	return out, nil
 }
 
-// DecodeGlyphToken decodes a single 4‑glyph token back to its exact word using the 4×7 unique scheme.
+// DecodeGlyphToken decodes a single 4‑glyph token back to its exact word using
+// the 4×7 unique scheme and the default SHAKE256 permutation RNG. Use
+// DecodeGlyphTokenWithPolicy to honor a KeyPolicy.RNG selection.
 //
 // Parameters:
 //   - tok: the 4‑glyph token to decode (must be exactly Len runes long)
@@ -75,11 +85,17 @@ func EncodeWords(input []string, index map[string]int, wordsList []string, key s
 //   - the decoded word
 //   - error if the token is invalid or parameters are inconsistent
 func DecodeGlyphToken(tok string, wordsList []string, key string) (string, error) {
+	return DecodeGlyphTokenWithPolicy(tok, wordsList, key, DefaultKeyPolicy())
+}
+
+// DecodeGlyphTokenWithPolicy is DecodeGlyphToken, but derives the permutation
+// via DeriveWithPolicy so policy.RNG selects the XOF (see perm.go).
+func DecodeGlyphTokenWithPolicy(tok string, wordsList []string, key string, policy KeyPolicy) (string, error) {
 	if len(wordsList) != Total {
 		return "", fmt.Errorf("wordsList length must be %d, got %d", Total, len(wordsList))
 	}
 
-	p, _ := Derive(len(wordsList), key)
+	p, _ := DeriveWithPolicy(len(wordsList), key, policy)
 
 	runes := []rune(strings.TrimSpace(tok))
 	if len(runes) != Len {
@@ -103,3 +119,48 @@ func DecodeGlyphToken(tok string, wordsList []string, key string) (string, error
 	}
 	return wordsList[idx], nil
 }
+
+// DecodeGlyphTokens decodes a batch of 4-glyph tokens back to their exact
+// words using the default SHAKE256 permutation RNG. Use
+// DecodeGlyphTokensWithPolicy to honor a KeyPolicy.RNG selection.
+func DecodeGlyphTokens(tokens []string, wordsList []string, key string) ([]string, error) {
+	return DecodeGlyphTokensWithPolicy(tokens, wordsList, key, DefaultKeyPolicy())
+}
+
+// DecodeGlyphTokensWithPolicy is DecodeGlyphTokens, but derives the
+// permutation once via DeriveWithPolicy and reuses it across every token,
+// rather than re-deriving per call like repeated DecodeGlyphTokenWithPolicy
+// calls would.
+func DecodeGlyphTokensWithPolicy(tokens []string, wordsList []string, key string, policy KeyPolicy) ([]string, error) {
+	if len(wordsList) != Total {
+		return nil, fmt.Errorf("wordsList length must be %d, got %d", Total, len(wordsList))
+	}
+
+	p, _ := DeriveWithPolicy(len(wordsList), key, policy)
+
+	out := make([]string, 0, len(tokens))
+	for _, tok := range tokens {
+		runes := []rune(strings.TrimSpace(tok))
+		if len(runes) != Len {
+			return nil, fmt.Errorf("glyph %q must be exactly %d symbols", tok, Len)
+		}
+		d := make([]int, Len)
+		for i, r := range runes {
+			val, ok := Decode[r]
+			if !ok {
+				return nil, fmt.Errorf("invalid glyph rune %q in %q", r, tok)
+			}
+			d[i] = val
+		}
+		code, ok := FromDigits(d)
+		if !ok {
+			return nil, fmt.Errorf("invalid glyph code %q", tok)
+		}
+		idx := p[code]
+		if idx < 0 || idx >= len(wordsList) {
+			return nil, fmt.Errorf("invalid glyph code %q", tok)
+		}
+		out = append(out, wordsList[idx])
+	}
+	return out, nil
+}
@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigVersion is bumped whenever the Config schema changes in a way that
+// requires migration. LoadConfig stamps it onto configs that predate the field.
+const ConfigVersion = 1
+
+// Config is the full on-disk shape of $XDG_CONFIG_HOME/glyphriot/config.toml.
+// The encode/decode flow and the share subcommand load it on startup, and
+// `init --print` displays it; the mnemonic subcommand does not read it.
+// Explicit flags still take precedence over whatever it contains.
+type Config struct {
+	ConfigVersion int             `toml:"config_version"`
+	KeyPolicy     ConfigKeyPolicy `toml:"key_policy"`
+	WordList      ConfigWordList  `toml:"wordlist"`
+	GlyphSep      string          `toml:"glyph_sep"`
+	Color         ConfigColor     `toml:"color"`
+}
+
+// ConfigKeyPolicy mirrors KeyPolicy in a TOML-friendly shape.
+type ConfigKeyPolicy struct {
+	KDF       string            `toml:"kdf"`
+	KDFParams map[string]string `toml:"kdf_params"`
+	AllowWeak bool              `toml:"allow_weak"`
+	RNG       string            `toml:"rng"`
+}
+
+// ConfigWordList selects the active wordlist. Structured as its own table so
+// additional lists (see the multi-language wordlist work) slot in without a
+// schema change.
+type ConfigWordList struct {
+	Active string `toml:"active"`
+}
+
+// ConfigColor controls ANSI styling. Watermark names a brand palette and is
+// saved and printed with the rest of the config, but no command reads it, so
+// QR output is not tinted by it.
+type ConfigColor struct {
+	Enabled   bool   `toml:"enabled"`
+	Watermark string `toml:"watermark"` // "", "btc", "xmr", or "zec"
+}
+
+// DefaultConfig returns the config this tool behaves as if no file exists.
+func DefaultConfig() Config {
+	p := DefaultKeyPolicy()
+	return Config{
+		ConfigVersion: ConfigVersion,
+		KeyPolicy: ConfigKeyPolicy{
+			KDF:       p.KDF,
+			KDFParams: p.KDFParams,
+			AllowWeak: p.AllowWeak,
+			RNG:       p.RNG,
+		},
+		WordList: ConfigWordList{Active: "bip39-en"},
+		GlyphSep: "",
+		Color:    ConfigColor{Enabled: true, Watermark: ""},
+	}
+}
+
+// ToKeyPolicy converts the config's key policy section into a KeyPolicy.
+func (c Config) ToKeyPolicy() KeyPolicy {
+	return KeyPolicy{
+		KDF:       c.KeyPolicy.KDF,
+		KDFParams: c.KeyPolicy.KDFParams,
+		AllowWeak: c.KeyPolicy.AllowWeak,
+		RNG:       c.KeyPolicy.RNG,
+	}
+}
+
+// ConfigPath returns the default config file location:
+// $XDG_CONFIG_HOME/glyphriot/config.toml, falling back to ~/.config when
+// XDG_CONFIG_HOME is unset, per the XDG Base Directory spec.
+func ConfigPath() (string, error) {
+	base := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME"))
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "glyphriot", "config.toml"), nil
+}
+
+// WriteDefaultConfig materializes DefaultConfig() at path (ConfigPath() if
+// path is empty). It refuses to clobber an existing file.
+func WriteDefaultConfig(path string) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		p, err := ConfigPath()
+		if err != nil {
+			return "", err
+		}
+		path = p
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("config already exists at %s (remove it first to regenerate)", path)
+		}
+		return "", fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer f.Close()
+	if err := toml.NewEncoder(f).Encode(DefaultConfig()); err != nil {
+		return "", fmt.Errorf("failed to write config: %w", err)
+	}
+	return path, nil
+}
+
+// LoadConfig reads path (ConfigPath() if empty), returning DefaultConfig() if
+// the file doesn't exist. A config_version of 0 (file predates the field) is
+// stamped to the current ConfigVersion so future migrations have a baseline.
+func LoadConfig(path string) (Config, error) {
+	if strings.TrimSpace(path) == "" {
+		p, err := ConfigPath()
+		if err != nil {
+			return Config{}, err
+		}
+		path = p
+	}
+	cfg := DefaultConfig()
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		if os.IsNotExist(err) {
+			return DefaultConfig(), nil
+		}
+		return Config{}, fmt.Errorf("failed to load config %s: %w", path, err)
+	}
+	if cfg.ConfigVersion == 0 {
+		cfg.ConfigVersion = ConfigVersion
+	}
+	if strings.TrimSpace(cfg.KeyPolicy.RNG) == "" {
+		cfg.KeyPolicy.RNG = "shake256"
+	}
+	return cfg, nil
+}
+
+// EncodeConfig writes cfg as TOML to w, for `glyphriot init --print`.
+func EncodeConfig(w io.Writer, cfg Config) error {
+	return toml.NewEncoder(w).Encode(cfg)
+}
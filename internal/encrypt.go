@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// encryptedGlyphPrefix marks a transport-encrypted glyph blob. The version tag
+// lets us change the inner format later without breaking older decoders that
+// only know how to reject (rather than misparse) a newer blob.
+const encryptedGlyphPrefix = "glyphriot-enc:v1:"
+
+// EncodeWordsEncrypted encodes input to glyphs exactly as EncodeWordsWithPolicy,
+// then wraps the space-joined glyph stream for a recipient so plaintext glyphs
+// never reach the terminal, clipboard, or chat scrollback. Exactly one of
+// ageRecipient or gpgKeyID must be set; the recipient key is transport-only and
+// is independent of KeyPolicy, which governs the permutation salt instead.
+func EncodeWordsEncrypted(input []string, index map[string]int, wordsList []string, key string, policy KeyPolicy, ageRecipient string, gpgKeyID string, gpgPubring io.Reader) (string, error) {
+	glyphs, err := EncodeWordsWithPolicy(input, index, wordsList, key, policy)
+	if err != nil {
+		return "", err
+	}
+	payload := strings.Join(glyphs, " ")
+
+	switch {
+	case ageRecipient != "" && gpgKeyID != "":
+		return "", fmt.Errorf("specify only one of --recipient or --recipient-gpg")
+	case ageRecipient != "":
+		return encryptToAge(payload, ageRecipient)
+	case gpgKeyID != "":
+		return encryptToGPG(payload, gpgKeyID, gpgPubring)
+	default:
+		return "", fmt.Errorf("no recipient provided")
+	}
+}
+
+func encryptToAge(payload, recipientStr string) (string, error) {
+	recipient, err := age.ParseX25519Recipient(recipientStr)
+	if err != nil {
+		return "", fmt.Errorf("invalid age recipient: %w", err)
+	}
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return "", fmt.Errorf("age encrypt failed: %w", err)
+	}
+	if _, err := io.WriteString(w, payload); err != nil {
+		return "", fmt.Errorf("age encrypt failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("age encrypt failed: %w", err)
+	}
+	return encryptedGlyphPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func encryptToGPG(payload, keyID string, pubring io.Reader) (string, error) {
+	if pubring == nil {
+		return "", fmt.Errorf("--recipient-gpg requires --gpg-pubring")
+	}
+	entities, err := openpgp.ReadArmoredKeyRing(pubring)
+	if err != nil {
+		return "", fmt.Errorf("invalid gpg pubring: %w", err)
+	}
+	recipient := findGPGEntity(entities, keyID)
+	if recipient == nil {
+		return "", fmt.Errorf("gpg key %q not found in pubring", keyID)
+	}
+	var buf bytes.Buffer
+	w, err := openpgp.Encrypt(&buf, []*openpgp.Entity{recipient}, nil, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("gpg encrypt failed: %w", err)
+	}
+	if _, err := io.WriteString(w, payload); err != nil {
+		return "", fmt.Errorf("gpg encrypt failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("gpg encrypt failed: %w", err)
+	}
+	return encryptedGlyphPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func findGPGEntity(entities openpgp.EntityList, keyID string) *openpgp.Entity {
+	keyID = strings.ToUpper(strings.TrimPrefix(keyID, "0x"))
+	for _, e := range entities {
+		if e.PrimaryKey == nil {
+			continue
+		}
+		if strings.HasSuffix(fmt.Sprintf("%X", e.PrimaryKey.Fingerprint), keyID) {
+			return e
+		}
+	}
+	return nil
+}
+
+// IsEncryptedGlyphBlob reports whether s looks like a blob produced by
+// EncodeWordsEncrypted, as opposed to a plain glyph token stream.
+func IsEncryptedGlyphBlob(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), encryptedGlyphPrefix)
+}
+
+// DecodeGlyphTokenEncrypted unwraps an encrypted glyph blob using the supplied
+// age identity or GPG private key, then decodes the recovered glyph stream with
+// DecodeGlyphTokensWithPolicy exactly as plaintext glyph input would be
+// decoded. Exactly one of ageIdentity or gpgPrivring must be set; passing an
+// encrypted blob without either is rejected rather than silently falling
+// through to the plaintext decode path.
+func DecodeGlyphTokenEncrypted(blob string, wordsList []string, key string, policy KeyPolicy, ageIdentity string, gpgPrivring io.Reader, gpgPassphrase []byte) ([]string, error) {
+	blob = strings.TrimSpace(blob)
+	if !IsEncryptedGlyphBlob(blob) {
+		return nil, fmt.Errorf("not an encrypted glyph blob")
+	}
+	if ageIdentity == "" && gpgPrivring == nil {
+		return nil, fmt.Errorf("encrypted glyph input requires --identity or --identity-gpg")
+	}
+	if ageIdentity != "" && gpgPrivring != nil {
+		return nil, fmt.Errorf("specify only one of --identity or --identity-gpg")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(blob, encryptedGlyphPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted glyph blob")
+	}
+
+	var payload string
+	switch {
+	case ageIdentity != "":
+		id, err := age.ParseX25519Identity(ageIdentity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age identity: %w", err)
+		}
+		r, err := age.Decrypt(bytes.NewReader(raw), id)
+		if err != nil {
+			return nil, fmt.Errorf("age decrypt failed")
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("age decrypt failed")
+		}
+		payload = string(out)
+	default:
+		entities, err := openpgp.ReadArmoredKeyRing(gpgPrivring)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gpg privring")
+		}
+		prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+			return gpgPassphrase, nil
+		}
+		md, err := openpgp.ReadMessage(bytes.NewReader(raw), entities, prompt, nil)
+		if err != nil {
+			return nil, fmt.Errorf("gpg decrypt failed")
+		}
+		out, err := io.ReadAll(md.UnverifiedBody)
+		if err != nil {
+			return nil, fmt.Errorf("gpg decrypt failed")
+		}
+		payload = string(out)
+	}
+
+	return DecodeGlyphTokensWithPolicy(strings.Fields(payload), wordsList, key, policy)
+}
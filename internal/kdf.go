@@ -0,0 +1,298 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF derives key material from a password and salt, and describes its own
+// parameters for serialization (KDFHeaderToken) and minimum-length guidance
+// (ValidateKeyStrength).
+type KDF interface {
+	// Derive produces outLen bytes of key material from password and salt.
+	Derive(password, salt []byte, outLen int) ([]byte, error)
+	// Params returns the parameters in effect, normalized to strings so they
+	// round-trip through KeyPolicy.KDFParams and the glyph header token.
+	Params() map[string]string
+	// MinRuneLength recommends a minimum passphrase length (in runes) to reach
+	// minBits of practical hardness under this KDF's configured cost.
+	MinRuneLength(minBits int) int
+}
+
+// kdfRegistry maps a KDF name to a constructor that validates/defaults the
+// supplied params and returns a ready-to-use KDF.
+var kdfRegistry = map[string]func(params map[string]string) (KDF, error){}
+
+// RegisterKDF adds (or replaces) a KDF constructor under name. Built-in KDFs
+// register themselves from init(); callers may register additional ones the
+// same way before looking them up.
+func RegisterKDF(name string, ctor func(params map[string]string) (KDF, error)) {
+	kdfRegistry[strings.ToLower(name)] = ctor
+}
+
+// LookupKDF resolves name to a configured KDF instance using params. params
+// may be nil, in which case each implementation applies its own defaults.
+func LookupKDF(name string, params map[string]string) (KDF, error) {
+	ctor, ok := kdfRegistry[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown KDF %q (supported: argon2id, scrypt, pbkdf2-sha256, none)", name)
+	}
+	return ctor(params)
+}
+
+func init() {
+	RegisterKDF("argon2id", newArgon2idKDF)
+	RegisterKDF("scrypt", newScryptKDF)
+	RegisterKDF("pbkdf2-sha256", newPBKDF2SHA256KDF)
+}
+
+// --- argon2id ---
+
+type argon2idKDF struct {
+	memMB, time uint32
+	parallel    uint8
+}
+
+func newArgon2idKDF(params map[string]string) (KDF, error) {
+	k := &argon2idKDF{memMB: 512, time: 3, parallel: 1}
+	if v, ok := params["mem_mb"]; ok {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("argon2id: invalid mem_mb %q", v)
+		}
+		k.memMB = uint32(n)
+	}
+	if v, ok := params["time"]; ok {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("argon2id: invalid time %q", v)
+		}
+		k.time = uint32(n)
+	}
+	if v, ok := params["parallel"]; ok {
+		n, err := strconv.ParseUint(v, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("argon2id: invalid parallel %q", v)
+		}
+		k.parallel = uint8(n)
+	}
+	if k.memMB == 0 || k.time == 0 || k.parallel == 0 {
+		return nil, fmt.Errorf("argon2id: mem_mb, time, and parallel must all be nonzero")
+	}
+	return k, nil
+}
+
+func (k *argon2idKDF) Derive(password, salt []byte, outLen int) ([]byte, error) {
+	return argon2.IDKey(password, salt, k.time, k.memMB*1024, k.parallel, uint32(outLen)), nil
+}
+
+func (k *argon2idKDF) Params() map[string]string {
+	return map[string]string{
+		"mem_mb":   strconv.FormatUint(uint64(k.memMB), 10),
+		"time":     strconv.FormatUint(uint64(k.time), 10),
+		"parallel": strconv.FormatUint(uint64(k.parallel), 10),
+	}
+}
+
+func (k *argon2idKDF) MinRuneLength(minBits int) int {
+	if minBits >= 256 {
+		return 20
+	}
+	return 16
+}
+
+// --- scrypt ---
+
+type scryptKDF struct {
+	n, r, p int
+}
+
+func newScryptKDF(params map[string]string) (KDF, error) {
+	k := &scryptKDF{n: 1 << 15, r: 8, p: 1}
+	if v, ok := params["n"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("scrypt: invalid n %q", v)
+		}
+		k.n = n
+	}
+	if v, ok := params["r"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("scrypt: invalid r %q", v)
+		}
+		k.r = n
+	}
+	if v, ok := params["p"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("scrypt: invalid p %q", v)
+		}
+		k.p = n
+	}
+	return k, nil
+}
+
+func (k *scryptKDF) Derive(password, salt []byte, outLen int) ([]byte, error) {
+	return scrypt.Key(password, salt, k.n, k.r, k.p, outLen)
+}
+
+func (k *scryptKDF) Params() map[string]string {
+	return map[string]string{
+		"n": strconv.Itoa(k.n),
+		"r": strconv.Itoa(k.r),
+		"p": strconv.Itoa(k.p),
+	}
+}
+
+func (k *scryptKDF) MinRuneLength(minBits int) int {
+	if minBits >= 256 {
+		return 20
+	}
+	return 16
+}
+
+// --- pbkdf2-sha256 ---
+
+type pbkdf2SHA256KDF struct {
+	iterations int
+}
+
+func newPBKDF2SHA256KDF(params map[string]string) (KDF, error) {
+	k := &pbkdf2SHA256KDF{iterations: 600000}
+	if v, ok := params["iterations"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("pbkdf2-sha256: invalid iterations %q", v)
+		}
+		k.iterations = n
+	}
+	if k.iterations <= 0 {
+		return nil, fmt.Errorf("pbkdf2-sha256: iterations must be positive")
+	}
+	return k, nil
+}
+
+func (k *pbkdf2SHA256KDF) Derive(password, salt []byte, outLen int) ([]byte, error) {
+	return pbkdf2.Key(password, salt, k.iterations, outLen, sha256.New), nil
+}
+
+func (k *pbkdf2SHA256KDF) Params() map[string]string {
+	return map[string]string{"iterations": strconv.Itoa(k.iterations)}
+}
+
+func (k *pbkdf2SHA256KDF) MinRuneLength(minBits int) int {
+	// PBKDF2-SHA256 is cheaper per guess than argon2id/scrypt at comparable
+	// iteration counts, so ask for four more characters at each tier.
+	if minBits >= 256 {
+		return 24
+	}
+	return 20
+}
+
+// --- header serialization ---
+
+// KDFHeaderToken serializes the active KDF name and parameters into a short,
+// versioned token ("kdf1.<name>.<params>.<hash8>") that can be prepended to
+// glyph output so decoding on another machine reconstructs the exact
+// derivation without the user re-specifying flags. The trailing hash guards
+// against a truncated or hand-edited token silently using different params.
+// Fields are dot-separated rather than hyphen-separated because <params> is
+// base64.RawURLEncoding, whose alphabet includes '-'; a hyphen delimiter could
+// collide with a byte inside that field and mis-split the token.
+func KDFHeaderToken(policy KeyPolicy) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(policy.KDF))
+	if name == "" {
+		name = "argon2id"
+	}
+	if name == "none" {
+		return "kdf1.none." + paramsToken(nil) + "." + paramsHash(nil), nil
+	}
+	kdf, err := LookupKDF(name, policy.KDFParams)
+	if err != nil {
+		return "", err
+	}
+	params := kdf.Params()
+	return "kdf1." + name + "." + paramsToken(params) + "." + paramsHash(params), nil
+}
+
+// ParseKDFHeaderToken parses a token produced by KDFHeaderToken, verifying the
+// trailing hash against the decoded params before returning them.
+func ParseKDFHeaderToken(tok string) (name string, params map[string]string, err error) {
+	parts := strings.SplitN(tok, ".", 4)
+	if len(parts) != 4 || parts[0] != "kdf1" {
+		return "", nil, fmt.Errorf("malformed KDF header token %q", tok)
+	}
+	params, err = paramsFromToken(parts[2])
+	if err != nil {
+		return "", nil, fmt.Errorf("malformed KDF header token %q: %w", tok, err)
+	}
+	if paramsHash(params) != parts[3] {
+		return "", nil, fmt.Errorf("KDF header token %q failed integrity check", tok)
+	}
+	return parts[1], params, nil
+}
+
+func paramsToken(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(params[k])
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(sb.String()))
+}
+
+func paramsFromToken(tok string) (map[string]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(tok)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{}
+	s := string(raw)
+	if s == "" {
+		return params, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid param pair %q", pair)
+		}
+		params[kv[0]] = kv[1]
+	}
+	return params, nil
+}
+
+func paramsHash(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(params[k])
+		sb.WriteByte(',')
+	}
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:4])
+}
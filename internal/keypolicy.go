@@ -8,21 +8,19 @@ import (
 	"fmt"
 	"strings"
 	"unicode/utf8"
-
-	"golang.org/x/crypto/argon2"
 )
 
 // KeyPolicy defines how we validate and derive the effective key material.
-//   - If KDF == "argon2id" (default), we use Argon2id to slow down brute force
-//     and enforce practical minimum lengths (ergonomic).
+//   - If KDF names a registered algorithm (see kdf.go: "argon2id" (default),
+//     "scrypt", "pbkdf2-sha256"), we run it with KDFParams to slow down brute
+//     force and enforce that KDF's own minimum-length recommendation.
 //   - If KDF == "none", we enforce pure minimum entropy by format (BIP‑39/hex/base64)
 //     and reject everything else unless AllowWeak == true.
 type KeyPolicy struct {
-	KDF         string // "argon2id" (default) or "none"
-	KDFMemMB    uint32 // memory in MB (e.g., 512)
-	KDFTime     uint32 // iterations (e.g., 3)
-	KDFParallel uint8  // parallelism (e.g., 1)
-	AllowWeak   bool   // allow weak keys (bypass enforcement)
+	KDF       string            // registry name ("argon2id", "scrypt", "pbkdf2-sha256") or "none"
+	KDFParams map[string]string // KDF-specific parameters (e.g. "mem_mb"/"time"/"parallel" for argon2id)
+	AllowWeak bool              // allow weak keys (bypass enforcement)
+	RNG       string            // permutation RNG: "shake256" (default) or "sha256ctr" for legacy compatibility
 }
 
 // DefaultKeyPolicy returns a recommended default that is ergonomic and strong.
@@ -30,11 +28,14 @@ type KeyPolicy struct {
 // Tweak to your environment if necessary (e.g., reduced mem on low-RAM hosts).
 func DefaultKeyPolicy() KeyPolicy {
 	return KeyPolicy{
-		KDF:         "argon2id",
-		KDFMemMB:    512,
-		KDFTime:     3,
-		KDFParallel: 1,
-		AllowWeak:   false,
+		KDF: "argon2id",
+		KDFParams: map[string]string{
+			"mem_mb":   "512",
+			"time":     "3",
+			"parallel": "1",
+		},
+		AllowWeak: false,
+		RNG:       "shake256",
 	}
 }
 
@@ -48,49 +49,43 @@ func MinBitsForContext(tokenOrWordCount int) int {
 }
 
 // EffectiveKeyMaterial derives a 32-byte seed from the provided key string using the given policy.
-//   - If policy.KDF == "argon2id": we run Argon2id with configured parameters and a fixed domain salt.
-//     The Argon2id output is then hashed with SHA-256 to get a canonical 32-byte seed.
+//   - If policy.KDF names a registered algorithm: we run it with policy.KDFParams
+//     against a fixed, algorithm-scoped domain salt, then hash the output once
+//     more with SHA-256 to get a canonical 32-byte seed.
 //   - If policy.KDF == "none": we directly SHA-256 the key string to 32 bytes.
 func EffectiveKeyMaterial(key string, policy KeyPolicy) ([32]byte, error) {
 	var seed32 [32]byte
 
-	switch strings.ToLower(strings.TrimSpace(policy.KDF)) {
-	case "", "argon2id":
-		// Use a domain-separated salt so the same passphrase doesn't collide across tools.
-		salt := []byte("GlyphRiot/v1/argon2id/domain-sep")
-		mem := policy.KDFMemMB
-		if mem == 0 {
-			mem = 512
-		}
-		time := policy.KDFTime
-		if time == 0 {
-			time = 3
-		}
-		par := policy.KDFParallel
-		if par == 0 {
-			par = 1
-		}
-
-		derived := argon2.IDKey([]byte(key), salt, time, mem*1024, par, 32)
-		// Hash once more to canonicalize
-		seed32 = sha256.Sum256(derived)
-		return seed32, nil
+	name := strings.ToLower(strings.TrimSpace(policy.KDF))
+	if name == "" {
+		name = "argon2id"
+	}
 
-	case "none":
+	if name == "none" {
 		seed32 = sha256.Sum256([]byte(key))
 		return seed32, nil
+	}
 
-	default:
-		return seed32, fmt.Errorf("unknown KDF %q (supported: argon2id, none)", policy.KDF)
+	kdf, err := LookupKDF(name, policy.KDFParams)
+	if err != nil {
+		return seed32, err
 	}
+
+	// Domain-separated per algorithm so the same passphrase doesn't collide
+	// across tools, or across KDFs within this tool.
+	salt := []byte("GlyphRiot/v1/" + name + "/domain-sep")
+	derived, err := kdf.Derive([]byte(key), salt, 32)
+	if err != nil {
+		return seed32, fmt.Errorf("%s: %w", name, err)
+	}
+	seed32 = sha256.Sum256(derived)
+	return seed32, nil
 }
 
 // ValidateKeyStrength enforces minimum key strength based on policy and required bits.
-// - With kdf=argon2id (default): enforce minimum passphrase length (ergonomic hardness).
-//   - For minBits=128 → require >=16 characters
-//   - For minBits=256 → require >=20 characters
-//     These are practical values given a strong KDF; feel free to tune upward.
-//
+// - With a registered KDF (default argon2id): ask the KDF for its own minimum
+//   passphrase length recommendation at this entropy tier (ergonomic hardness
+//   given its configured cost) and enforce that.
 // - With kdf=none: enforce pure entropy by format only (no guessing the "quality" of ASCII).
 //   - Accept a 12- or 24-word BIP‑39 phrase as the key (128/256 bits).
 //   - Accept hex with length >= minBits/4.
@@ -105,25 +100,12 @@ func ValidateKeyStrength(key string, minBits int, policy KeyPolicy) error {
 		return fmt.Errorf("key is empty; provide a strong key or use --allow-weak-key")
 	}
 
-	switch strings.ToLower(strings.TrimSpace(policy.KDF)) {
-	case "", "argon2id":
-		// Practical hardness with KDF: enforce minimal passphrase length thresholds.
-		var minLen int
-		if minBits >= 256 {
-			minLen = 20
-		} else {
-			minLen = 16
-		}
-		// Count runes (not bytes) to avoid trivially bypassing with multi-byte empty-like inputs.
-		if utf8.RuneCountInString(key) < minLen {
-			if policy.AllowWeak {
-				return nil
-			}
-			return fmt.Errorf("key too short: need %d+ characters for this context with Argon2id enabled (or supply a 24-word BIP‑39 phrase, 64+ hex chars, or 32-byte base64; or use --allow-weak-key)", minLen)
-		}
-		return nil
+	name := strings.ToLower(strings.TrimSpace(policy.KDF))
+	if name == "" {
+		name = "argon2id"
+	}
 
-	case "none":
+	if name == "none" {
 		// Pure entropy enforcement by accepted format
 		if ok := satisfiesPureEntropyFormats(key, minBits); ok {
 			return nil
@@ -133,13 +115,25 @@ func ValidateKeyStrength(key string, minBits int, policy KeyPolicy) error {
 		}
 		return fmt.Errorf("key does not meet %d-bit minimum. Use a 12/24-word BIP‑39 phrase, %d+ hex chars, or base64 of %d+ bytes; or use --allow-weak-key",
 			minBits, minBits/4, minBits/8)
+	}
+
+	kdf, err := LookupKDF(name, policy.KDFParams)
+	if err != nil {
+		if policy.AllowWeak {
+			return nil
+		}
+		return err
+	}
 
-	default:
+	// Count runes (not bytes) to avoid trivially bypassing with multi-byte empty-like inputs.
+	minLen := kdf.MinRuneLength(minBits)
+	if utf8.RuneCountInString(key) < minLen {
 		if policy.AllowWeak {
 			return nil
 		}
-		return fmt.Errorf("unknown KDF %q (supported: argon2id, none)", policy.KDF)
+		return fmt.Errorf("key too short: need %d+ characters for this context with %s enabled (or supply a 24-word BIP‑39 phrase, 64+ hex chars, or 32-byte base64; or use --allow-weak-key)", minLen, name)
 	}
+	return nil
 }
 
 // satisfiesPureEntropyFormats returns true if key (a string) meets the pure entropy
@@ -160,23 +154,18 @@ func satisfiesPureEntropyFormats(key string, minBits int) bool {
 	return false
 }
 
-// bip39BitsIfValid checks if the key is a 12- or 24-word BIP‑39 English phrase.
-// We validate that all words are in the canonical list; checksum is not required here
-// because for key-space purposes we only need the entropy tier (128/256).
+// bip39BitsIfValid checks whether the key is a checksum-valid BIP‑39 English
+// phrase (12 or 24 words) via ValidateMnemonic. Dictionary membership alone is
+// not enough: a 12-word string of valid-but-unrelated words still fails here,
+// since only 1 in CS-bits-worth of word combinations carries a correct
+// checksum.
 func bip39BitsIfValid(key string) (int, bool) {
 	words := normalizedWords(key)
 	if len(words) != 12 && len(words) != 24 {
 		return 0, false
 	}
-	// Build index for quick lookup once per call; small map, negligible cost
-	bipIndex := make(map[string]struct{}, len(WordsBIP39EN))
-	for _, w := range WordsBIP39EN {
-		bipIndex[strings.ToLower(strings.TrimSpace(w))] = struct{}{}
-	}
-	for _, w := range words {
-		if _, ok := bipIndex[w]; !ok {
-			return 0, false
-		}
+	if err := ValidateMnemonic(words); err != nil {
+		return 0, false
 	}
 	if len(words) == 12 {
 		return 128, true
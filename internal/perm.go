@@ -4,11 +4,42 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"strings"
+
+	"golang.org/x/crypto/sha3"
 )
 
-// drbg implements a deterministic CSPRNG using SHA-256 in counter mode:
-// buf = SHA256(seed || counter); counter++
-// nextUint64() draws 8 bytes from the buffer, refilling as needed.
+// RandSource is a stream of uniformly distributed integers, used to drive the
+// Fisher–Yates shuffle in Derive. Both built-in implementations below satisfy
+// it; a third (e.g. a test double) must provide both methods, and within this
+// package its RandInt can delegate to rejectionSample as theirs do.
+type RandSource interface {
+	NextUint64() uint64
+	RandInt(n int) int
+}
+
+// rejectionSample returns a uniform integer in [0, n) by rejection sampling
+// over src.NextUint64(), shared by every RandSource implementation so the
+// unbiased-draw logic lives in exactly one place.
+func rejectionSample(src interface{ NextUint64() uint64 }, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	N := uint64(n)
+	max := ^uint64(0)
+	limit := (max / N) * N // largest multiple of N <= max
+	for {
+		r := src.NextUint64()
+		if r < limit {
+			return int(r % N)
+		}
+	}
+}
+
+// drbg implements the legacy CSPRNG: SHA-256 in counter mode,
+// buf = SHA256(seed || counter); counter++. Re-hashing per 32 bytes is
+// wasteful for a 2048-element shuffle; kept only so KeyPolicy.RNG ==
+// "sha256ctr" reproduces permutations generated before SHAKE256 became the
+// default.
 type drbg struct {
 	seed    [32]byte
 	counter uint64
@@ -36,7 +67,7 @@ func (d *drbg) refill() {
 	d.counter++
 }
 
-func (d *drbg) nextUint64() uint64 {
+func (d *drbg) NextUint64() uint64 {
 	var out uint64
 	for i := 0; i < 8; i++ {
 		if d.off >= len(d.buf) {
@@ -48,27 +79,72 @@ func (d *drbg) nextUint64() uint64 {
 	return out
 }
 
-// randInt returns a uniform integer in [0, n) using rejection sampling.
-func (d *drbg) randInt(n int) int {
-	if n <= 0 {
-		return 0
-	}
-	N := uint64(n)
-	max := ^uint64(0)
-	limit := (max / N) * N // largest multiple of N <= max
-	var r uint64
-	for {
-		r = d.nextUint64()
-		if r < limit {
-			return int(r % N)
+func (d *drbg) RandInt(n int) int {
+	return rejectionSample(d, n)
+}
+
+// shakeXOF is a SHAKE256-based RandSource: the key is absorbed once, then
+// bytes are squeezed on demand into an internal buffer that NextUint64
+// drains, refilling by squeezing more. Unlike drbg, this has no per-chunk
+// rehash cost and gives arbitrary-length uniform output directly from the
+// sponge.
+type shakeXOF struct {
+	sponge sha3.ShakeHash
+	buf    [4096]byte
+	filled int
+	off    int
+}
+
+func newShakeXOF(seedMaterial []byte) *shakeXOF {
+	sponge := sha3.NewShake256()
+	sponge.Write(seedMaterial)
+	x := &shakeXOF{sponge: sponge}
+	x.refill()
+	return x
+}
+
+func (x *shakeXOF) refill() {
+	n, _ := x.sponge.Read(x.buf[:])
+	x.filled = n
+	x.off = 0
+}
+
+func (x *shakeXOF) NextUint64() uint64 {
+	var out uint64
+	for i := 0; i < 8; i++ {
+		if x.off >= x.filled {
+			x.refill()
 		}
+		out = (out << 8) | uint64(x.buf[x.off])
+		x.off++
 	}
+	return out
+}
+
+func (x *shakeXOF) RandInt(n int) int {
+	return rejectionSample(x, n)
 }
 
 // Derive returns a deterministic permutation of [0..n-1] and its inverse,
-// seeded from SHA-256(key). If key is empty or whitespace, a fixed default
-// seed is used for stable behavior.
+// seeded from key via a SHAKE256 XOF. If key is empty or whitespace, a fixed
+// identity permutation is used for stable behavior. Use DeriveWithPolicy to
+// pin the legacy SHA-256/counter-mode RNG for backward-compatible permutations.
 func Derive(n int, key string) ([]int, []int) {
+	return deriveWithSource(n, key, func(seed []byte) RandSource { return newShakeXOF(seed) })
+}
+
+// DeriveWithPolicy is Derive, but selects the RandSource named by
+// policy.RNG ("shake256", the default, or "sha256ctr" for the legacy DRBG).
+func DeriveWithPolicy(n int, key string, policy KeyPolicy) ([]int, []int) {
+	switch strings.ToLower(strings.TrimSpace(policy.RNG)) {
+	case "sha256ctr":
+		return deriveWithSource(n, key, func(seed []byte) RandSource { return newDRBG(seed) })
+	default:
+		return deriveWithSource(n, key, func(seed []byte) RandSource { return newShakeXOF(seed) })
+	}
+}
+
+func deriveWithSource(n int, key string, newSource func(seedMaterial []byte) RandSource) ([]int, []int) {
 	if n <= 0 {
 		return []int{}, []int{}
 	}
@@ -82,16 +158,14 @@ func Derive(n int, key string) ([]int, []int) {
 		return p, Inv(p)
 	}
 
-	// Deterministic CSPRNG based on SHA-256(key || counter) in counter mode.
-	// Used with unbiased Fisher–Yates to guarantee a uniform permutation.
-	drbg := newDRBG([]byte(key))
+	src := newSource([]byte(key))
 
 	p := make([]int, n)
 	for i := 0; i < n; i++ {
 		p[i] = i
 	}
 	for i := n - 1; i > 0; i-- {
-		j := drbg.randInt(i + 1) // j ∈ [0, i], unbiased
+		j := src.RandInt(i + 1) // j ∈ [0, i], unbiased
 		p[i], p[j] = p[j], p[i]
 	}
 
@@ -0,0 +1,370 @@
+// Package shamir implements Shamir's Secret Sharing over GF(256), rendering
+// each share as a glyph string in the same 4×7 alphabet the rest of
+// GlyphRiot uses for word encoding (see internal.Digits).
+//
+// Secret sharing: one independent degree-(threshold-1) polynomial per secret
+// byte, over GF(256) with the AES/Rijndael reduction polynomial 0x11b.
+// Share i (1..total) carries f(i) for every byte position; CombineKey
+// recovers f(0) = the secret byte via Lagrange interpolation at x=0.
+//
+// Share framing: [header][payloadLen][payload...][crc16], where header packs
+// (threshold-1)<<4 | (shareIndex-1) into a single byte (so threshold and
+// total are each limited to 1..16) and crc16 guards against corruption of an
+// individual share before combination ever runs. The frame is then rendered
+// as glyphs: a 4-glyph length prefix (so the decoder knows exactly how many
+// bytes to reconstruct regardless of leading zero bytes) followed by the
+// frame itself encoded as one big-endian base-7 integer, padded with leading
+// zero digits to a multiple of 4 glyphs.
+//
+// SplitMnemonic/CombineMnemonic apply the same scheme to a BIP-39 mnemonic's
+// entropy bytes rather than arbitrary secret bytes, so seed phrase backups can
+// be split and recombined without ever handling the raw passphrase.
+package shamir
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"glyphriot/internal"
+)
+
+// MaxShares is the largest share index (and threshold) the 1-byte header can
+// encode: (M-1)<<4 | (shareIndex-1) needs both nibbles to fit in 0..15.
+const MaxShares = 16
+
+// --- GF(256) arithmetic (AES/Rijndael polynomial 0x11b) ---
+
+var gfExp [510]byte
+var gfLog [256]byte
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		gfExp[i] = x
+		gfLog[x] = byte(i)
+		x = gfMulNoTable(x, 0x03)
+	}
+	for i := 255; i < 510; i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMulNoTable(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8 && a != 0 && b != 0; i++ {
+		if b&1 != 0 {
+			p ^= a
+		}
+		hiBitSet := a & 0x80
+		a <<= 1
+		if hiBitSet != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) (byte, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("shamir: division by zero in GF(256)")
+	}
+	if a == 0 {
+		return 0, nil
+	}
+	diff := int(gfLog[a]) - int(gfLog[b])
+	if diff < 0 {
+		diff += 255
+	}
+	return gfExp[diff], nil
+}
+
+// gfEval evaluates poly (coefficients low-to-high, poly[0] is the constant
+// term) at x via Horner's method.
+func gfEval(poly []byte, x byte) byte {
+	var result byte
+	for i := len(poly) - 1; i >= 0; i-- {
+		result = gfMul(result, x) ^ poly[i]
+	}
+	return result
+}
+
+// --- Splitting / combining ---
+
+// SplitKey splits secret into total shares such that any threshold of them
+// reconstruct it exactly, and renders each share as a glyph string.
+func SplitKey(secret []byte, threshold, total int) ([]string, error) {
+	if threshold < 1 || total < 1 {
+		return nil, fmt.Errorf("shamir: threshold and total must be positive")
+	}
+	if threshold > total {
+		return nil, fmt.Errorf("shamir: threshold (%d) cannot exceed total shares (%d)", threshold, total)
+	}
+	if total > MaxShares {
+		return nil, fmt.Errorf("shamir: total shares (%d) exceeds the maximum of %d", total, MaxShares)
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("shamir: secret must not be empty")
+	}
+	if len(secret) > 255 {
+		return nil, fmt.Errorf("shamir: secret must be 255 bytes or fewer, got %d", len(secret))
+	}
+
+	// One polynomial per secret byte: poly[0] = secret byte, the rest random.
+	polys := make([][]byte, len(secret))
+	for i, b := range secret {
+		poly := make([]byte, threshold)
+		poly[0] = b
+		if threshold > 1 {
+			if _, err := rand.Read(poly[1:]); err != nil {
+				return nil, fmt.Errorf("shamir: failed to generate polynomial: %w", err)
+			}
+		}
+		polys[i] = poly
+	}
+
+	shares := make([]string, total)
+	for shareIdx := 1; shareIdx <= total; shareIdx++ {
+		payload := make([]byte, len(secret))
+		for i, poly := range polys {
+			payload[i] = gfEval(poly, byte(shareIdx))
+		}
+
+		header := byte((threshold-1)<<4 | (shareIdx - 1))
+		frame := make([]byte, 0, 2+len(payload)+2)
+		frame = append(frame, header, byte(len(payload)))
+		frame = append(frame, payload...)
+		sum := crc16(frame)
+		frame = append(frame, byte(sum>>8), byte(sum))
+
+		glyph, err := encodeFrame(frame)
+		if err != nil {
+			return nil, err
+		}
+		shares[shareIdx-1] = glyph
+	}
+	return shares, nil
+}
+
+// CombineKey reconstructs the original secret from at least threshold shares
+// produced by SplitKey. Too few shares produce a "need at least M shares"
+// error, shares whose headers disagree on threshold or length are rejected,
+// and a corrupted share fails its own CRC. The CRC covers each share alone,
+// not the reconstructed secret, so threshold-many shares taken from different
+// splits with the same threshold and length are not detected and yield a
+// wrong secret.
+func CombineKey(shares []string) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("shamir: no shares provided")
+	}
+
+	type parsedShare struct {
+		x       byte
+		payload []byte
+	}
+	parsed := make([]parsedShare, 0, len(shares))
+	var payloadLen = -1
+	var threshold = -1
+
+	for i, s := range shares {
+		frame, err := decodeFrame(s)
+		if err != nil {
+			return nil, fmt.Errorf("shamir: share %d: %w", i+1, err)
+		}
+		if len(frame) < 4 {
+			return nil, fmt.Errorf("shamir: share %d: frame too short", i+1)
+		}
+		header := frame[0]
+		plen := int(frame[1])
+		if len(frame) != 2+plen+2 {
+			return nil, fmt.Errorf("shamir: share %d: length mismatch", i+1)
+		}
+		payload := frame[2 : 2+plen]
+		wantSum := crc16(frame[:2+plen])
+		gotSum := uint16(frame[2+plen])<<8 | uint16(frame[3+plen])
+		if wantSum != gotSum {
+			return nil, fmt.Errorf("shamir: share %d: checksum mismatch (corrupted share)", i+1)
+		}
+
+		thisThreshold := int(header>>4) + 1
+		x := byte(header&0x0f) + 1
+
+		if payloadLen == -1 {
+			payloadLen = plen
+			threshold = thisThreshold
+		} else if plen != payloadLen {
+			return nil, fmt.Errorf("shamir: share %d: secret length mismatch with earlier shares", i+1)
+		} else if thisThreshold != threshold {
+			return nil, fmt.Errorf("shamir: share %d: threshold mismatch with earlier shares", i+1)
+		}
+		parsed = append(parsed, parsedShare{x: x, payload: payload})
+	}
+
+	if len(parsed) < threshold {
+		return nil, fmt.Errorf("shamir: need at least %d shares, got %d", threshold, len(parsed))
+	}
+	parsed = parsed[:threshold]
+
+	secret := make([]byte, payloadLen)
+	for byteIdx := 0; byteIdx < payloadLen; byteIdx++ {
+		var sum byte
+		for i, si := range parsed {
+			// Lagrange basis polynomial L_i(0) for the point set {x_1..x_threshold}.
+			num := byte(1)
+			den := byte(1)
+			for j, sj := range parsed {
+				if i == j {
+					continue
+				}
+				num = gfMul(num, sj.x)
+				den = gfMul(den, sj.x^si.x)
+			}
+			coeff, err := gfDiv(num, den)
+			if err != nil {
+				return nil, fmt.Errorf("shamir: %w", err)
+			}
+			sum ^= gfMul(si.payload[byteIdx], coeff)
+		}
+		secret[byteIdx] = sum
+	}
+	return secret, nil
+}
+
+// crc16 computes CRC-16/CCITT-FALSE (poly 0x1021, init 0xFFFF) over data.
+func crc16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = (crc << 1) ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// --- BIP-39 mnemonic sharing ---
+
+// SplitMnemonic splits a checksum-valid BIP-39 mnemonic's entropy (not the
+// word strings themselves) into total glyph-encoded shares, any threshold of
+// which reconstruct the original phrase via CombineMnemonic.
+func SplitMnemonic(words []string, threshold, total int) ([]string, error) {
+	entropy, err := internal.MnemonicToEntropy(words)
+	if err != nil {
+		return nil, fmt.Errorf("shamir: %w", err)
+	}
+	return SplitKey(entropy, threshold, total)
+}
+
+// CombineMnemonic reconstructs the entropy behind a SplitMnemonic call from at
+// least threshold shares and re-encodes it as its BIP-39 mnemonic.
+func CombineMnemonic(shares []string) ([]string, error) {
+	entropy, err := CombineKey(shares)
+	if err != nil {
+		return nil, err
+	}
+	words, err := internal.EntropyToMnemonic(entropy)
+	if err != nil {
+		return nil, fmt.Errorf("shamir: recovered entropy is not a valid mnemonic length: %w", err)
+	}
+	return words, nil
+}
+
+// --- glyph framing ---
+
+func encodeFrame(frame []byte) (string, error) {
+	if len(frame) >= 1<<16 {
+		return "", fmt.Errorf("shamir: frame too large to encode")
+	}
+	lenDigits := intToBase7Digits(len(frame), 4)
+
+	n := new(big.Int).SetBytes(frame)
+	blobDigits := bigIntToBase7Digits(n)
+	if pad := (internal.Len - len(blobDigits)%internal.Len) % internal.Len; pad > 0 {
+		blobDigits = append(make([]int, pad), blobDigits...)
+	}
+
+	all := append(lenDigits, blobDigits...)
+	var sb strings.Builder
+	for _, d := range all {
+		sb.WriteRune(internal.Digits[d])
+	}
+	return sb.String(), nil
+}
+
+func decodeFrame(s string) ([]byte, error) {
+	s = internal.StripSepAndSpaces(strings.TrimSpace(s), "")
+	runes := []rune(s)
+	digits := make([]int, len(runes))
+	for i, r := range runes {
+		d, ok := internal.Decode[r]
+		if !ok {
+			return nil, fmt.Errorf("invalid glyph rune %q", r)
+		}
+		digits[i] = d
+	}
+	if len(digits) < 4 {
+		return nil, fmt.Errorf("share too short")
+	}
+	frameLen := base7DigitsToInt(digits[:4])
+	n := base7DigitsToBigInt(digits[4:])
+	frame := n.FillBytes(make([]byte, frameLen))
+	return frame, nil
+}
+
+func intToBase7Digits(v, width int) []int {
+	digits := make([]int, width)
+	for i := width - 1; i >= 0; i-- {
+		digits[i] = v % 7
+		v /= 7
+	}
+	return digits
+}
+
+func base7DigitsToInt(digits []int) int {
+	v := 0
+	for _, d := range digits {
+		v = v*7 + d
+	}
+	return v
+}
+
+func bigIntToBase7Digits(n *big.Int) []int {
+	if n.Sign() == 0 {
+		return nil
+	}
+	seven := big.NewInt(7)
+	tmp := new(big.Int).Set(n)
+	mod := new(big.Int)
+	var digits []int
+	for tmp.Sign() > 0 {
+		tmp.DivMod(tmp, seven, mod)
+		digits = append(digits, int(mod.Int64()))
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return digits
+}
+
+func base7DigitsToBigInt(digits []int) *big.Int {
+	n := new(big.Int)
+	seven := big.NewInt(7)
+	for _, d := range digits {
+		n.Mul(n, seven)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+	return n
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/text/unicode/norm"
+)
+
+// glyphSigDelimiter separates canonicalized glyph tokens before hashing. U+001F
+// (Unit Separator) is chosen because it cannot appear in any glyph rune or in
+// normal passphrase/word input, so it can't be smuggled in to forge a collision
+// between two differently-segmented token lists.
+const glyphSigDelimiter = ""
+
+// canonicalizeGlyphs strips the given separator and all Unicode whitespace from
+// each glyph token, NFC-normalizes it, joins the tokens with glyphSigDelimiter,
+// and prefixes the result with its own big-endian length. The length prefix
+// binds the message length into the signed bytes so truncation can't masquerade
+// as a differently-sized but still delimiter-consistent glyph set.
+func canonicalizeGlyphs(glyphs []string, sep string) []byte {
+	normalized := make([]string, len(glyphs))
+	for i, g := range glyphs {
+		normalized[i] = norm.NFC.String(StripSepAndSpaces(g, sep))
+	}
+	joined := []byte(joinGlyphs(normalized))
+
+	var lenPrefix [4]byte
+	binary.BigEndian.PutUint32(lenPrefix[:], uint32(len(joined)))
+	return append(lenPrefix[:], joined...)
+}
+
+func joinGlyphs(tokens []string) string {
+	out := ""
+	for i, t := range tokens {
+		if i > 0 {
+			out += glyphSigDelimiter
+		}
+		out += t
+	}
+	return out
+}
+
+// SignGlyphs produces a detached Ed25519 signature over the canonicalized glyph
+// set (see canonicalizeGlyphs), signing its SHA-512 hash. sep is the separator
+// (if any) the caller inserted between glyphs for display; it is stripped
+// before hashing so the signature is display-agnostic.
+func SignGlyphs(glyphs []string, sep string, priv ed25519.PrivateKey) ([]byte, error) {
+	if len(glyphs) == 0 {
+		return nil, fmt.Errorf("sign: no glyphs provided")
+	}
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("sign: invalid Ed25519 private key size %d", len(priv))
+	}
+	sum := sha512.Sum512(canonicalizeGlyphs(glyphs, sep))
+	return ed25519.Sign(priv, sum[:]), nil
+}
+
+// VerifyGlyphs checks sig against the canonicalized glyph set (whitespace
+// stripped, NFC-normalized) using pub. Callers that display glyphs with a
+// custom separator must strip it themselves before calling, since
+// canonicalization here only removes Unicode whitespace.
+func VerifyGlyphs(glyphs []string, sig []byte, pub ed25519.PublicKey) error {
+	if len(glyphs) == 0 {
+		return fmt.Errorf("verify: no glyphs provided")
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("verify: invalid Ed25519 public key size %d", len(pub))
+	}
+	sum := sha512.Sum512(canonicalizeGlyphs(glyphs, ""))
+	if !ed25519.Verify(pub, sum[:], sig) {
+		return fmt.Errorf("verify: signature does not match glyphs")
+	}
+	return nil
+}
+
+// DeriveSigningKey deterministically derives an Ed25519 keypair from
+// passphrase so that anyone who later has the passphrase (and the same
+// policy) can regenerate the verifying key without storing it separately.
+// The passphrase first goes through MustEffectiveKeyMaterial (enforcing
+// minBits under policy, same as the rest of the encode/decode path), then
+// HKDF-SHA512 expands that seed into the 32-byte Ed25519 seed, domain-
+// separated so this never collides with the permutation or KDF uses of the
+// same effective key material.
+func DeriveSigningKey(passphrase string, minBits int, policy KeyPolicy) (ed25519.PrivateKey, ed25519.PublicKey, error) {
+	seed32, err := MustEffectiveKeyMaterial(passphrase, minBits, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := hkdf.New(sha512.New, seed32[:], nil, []byte("GlyphRiot/v1/ed25519-signing-key"))
+	edSeed := make([]byte, ed25519.SeedSize)
+	if _, err := h.Read(edSeed); err != nil {
+		return nil, nil, fmt.Errorf("sign: failed to derive signing key: %w", err)
+	}
+
+	priv := ed25519.NewKeyFromSeed(edSeed)
+	pub := priv.Public().(ed25519.PublicKey)
+	return priv, pub, nil
+}
@@ -1,153 +1,445 @@
 package internal
 
 import (
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
-	"math/rand"
+	"io"
 	"os"
+	"runtime"
 	"strings"
-	"time"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
 )
 
-// RunSelfTest generates randomized self-test sets of size specified in `sets`,
-// prints each set (words and glyphs), verifies exact round-trip decoding, and
-// returns the number of failed sets.
-//
-// Parameters:
-// - wordsList: the canonical word list (length must be Total, e.g., 2048)
-// - index:     map of lowercased word -> index in wordsList
-// - keyStr:    key/salt for permutation ("" = identity order)
-// - glyphSep:  optional separator to insert between glyphs for readability
-// - paginate:  whether to paginate output when the terminal is a TTY
-// - height:    terminal height in rows for pagination logic
-// - sets:      slice of test sizes (e.g., []int{12, 24})
-// - title:     heading to print once at the top (empty to skip)
-func RunSelfTest(wordsList []string, index map[string]int, keyStr string, glyphSep string, paginate bool, height int, sets []int, title string) int {
-	perm, _ := Derive(len(wordsList), keyStr)
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	failed := 0
+// SelfTestSetResult is the outcome of one encode/decode round-trip set.
+type SelfTestSetResult struct {
+	Index     int      `json:"index"`  // position within the requested set-size sequence
+	Size      int      `json:"size"`   // word-set size (e.g. 12 or 24)
+	Words     []string `json:"words"`  // the generated words
+	Glyphs    []string `json:"glyphs"` // the encoded glyph tokens (no separator)
+	Passed    bool     `json:"passed"`
+	FailIndex int      `json:"fail_index"` // position of the first mismatch, or -1
+	Expected  string   `json:"expected,omitempty"`
+	Decoded   string   `json:"decoded,omitempty"`
+	EncodeErr string   `json:"encode_err,omitempty"` // set only if encoding itself failed
+	DecodeErr string   `json:"decode_err,omitempty"` // set only if batch decoding itself failed
+}
 
-	printed := 0
-	header := func() {
-		if title != "" {
-			fmt.Println(Style(title, Bold, Blue))
-			printed++
-		}
+// SelfTestResult summarizes a full SelfTestRunner.Run call.
+type SelfTestResult struct {
+	Title      string              `json:"title"`
+	TotalSets  int                 `json:"total_sets"`
+	FailedSets int                 `json:"failed_sets"`
+	Sets       []SelfTestSetResult `json:"sets"`
+}
+
+// Reporter receives self-test progress and results as a SelfTestRunner runs.
+// ReportSet is called at most once per requested set, in the same order as
+// SelfTestRunner.Sets, even though the sets themselves are computed in parallel;
+// delivery stops early once a Reporter that implements Quit reports true.
+type Reporter interface {
+	ReportHeader(title string, totalSets int)
+	ReportSet(set SelfTestSetResult)
+	ReportSummary(result SelfTestResult)
+}
+
+// quitter lets a Reporter signal (e.g. after a paginated "q" keypress) that the
+// runner should stop delivering further ReportSet calls. Reporters that don't
+// support early exit simply don't implement it.
+type quitter interface {
+	Quit() bool
+}
+
+// SelfTestRunner generates randomized self-test sets, verifies each one
+// round-trips through encode/decode exactly, and delivers results to a
+// Reporter.
+type SelfTestRunner struct {
+	WordsList []string
+	Index     map[string]int
+	Key       string    // key/salt for permutation ("" = identity order)
+	Policy    KeyPolicy // selects the permutation RNG (Policy.RNG) exercised by Run/runSet
+	Sets      []int     // slice of test sizes (e.g. []int{12, 24})
+
+	// Seed pins the master DRBG seed for reproducible failures; if empty, it is
+	// derived from SHAKE256(Key || "selftest"). Each set gets its own
+	// independent stream, derived from this seed by counter, so sets can run
+	// concurrently without sharing RNG state.
+	Seed []byte
+
+	// Workers caps how many sets run concurrently. <= 0 means
+	// runtime.NumCPU(), capped to len(Sets).
+	Workers int
+}
+
+// NewSelfTestRunner builds a SelfTestRunner with default seeding and worker
+// count, exercising policy.RNG the same way real encode/decode would.
+func NewSelfTestRunner(wordsList []string, index map[string]int, key string, policy KeyPolicy, sets []int) *SelfTestRunner {
+	return &SelfTestRunner{WordsList: wordsList, Index: index, Key: key, Policy: policy, Sets: sets}
+}
+
+func (r *SelfTestRunner) masterSeed() []byte {
+	if len(r.Seed) > 0 {
+		return r.Seed
 	}
+	h := sha3.NewShake256()
+	h.Write([]byte(r.Key))
+	h.Write([]byte("selftest"))
+	seed := make([]byte, 32)
+	io.ReadFull(h, seed)
+	return seed
+}
+
+// shardSeed derives an independent 32-byte seed for set i from the master
+// seed by counter: the first 32 bytes of SHAKE256(master || big-endian uint64 i).
+func shardSeed(master []byte, i int) []byte {
+	h := sha3.NewShake256()
+	h.Write(master)
+	var ctr [8]byte
+	binary.BigEndian.PutUint64(ctr[:], uint64(i))
+	h.Write(ctr[:])
+	seed := make([]byte, 32)
+	io.ReadFull(h, seed)
+	return seed
+}
+
+// Run shards r.Sets across a worker pool, then delivers results to reporter in
+// Sets order (computation is parallel; reporting is sequential so paginated
+// text output and JUnit/JSON output stay deterministic).
+func (r *SelfTestRunner) Run(reporter Reporter, title string) SelfTestResult {
+	reporter.ReportHeader(title, len(r.Sets))
+
+	master := r.masterSeed()
+	perm, _ := DeriveWithPolicy(len(r.WordsList), r.Key, r.Policy)
+	results := make([]SelfTestSetResult, len(r.Sets))
 
-	if paginate {
-		header()
+	workers := r.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(r.Sets) {
+		workers = len(r.Sets)
+	}
+	if workers < 1 {
+		workers = 1
 	}
 
-	for si, sz := range sets {
-		// Assemble a randomized, non-repeating set of positions in [0..n-1]
-		seen := make(map[int]bool, sz)
-		seq := make([]int, 0, sz)
-		for len(seq) < sz {
-			p := r.Intn(len(wordsList))
-			if seen[p] {
-				continue
+	type job struct {
+		idx, size int
+	}
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results[j.idx] = r.runSet(j.idx, j.size, perm, shardSeed(master, j.idx))
 			}
-			seen[p] = true
-			seq = append(seq, p)
-		}
-
-		// Build the test words via permutation (pos -> word index)
-		words := make([]string, sz)
-		for i := 0; i < sz; i++ {
-			words[i] = wordsList[perm[seq[i]]]
-		}
+		}()
+	}
+	for i, sz := range r.Sets {
+		jobs <- job{idx: i, size: sz}
+	}
+	close(jobs)
+	wg.Wait()
 
-		// Encode to glyphs
-		glyphs, err := EncodeWords(words, index, wordsList, keyStr)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "self-test encode error: %v\n", err)
+	failed := 0
+	for _, set := range results {
+		reporter.ReportSet(set)
+		if !set.Passed {
 			failed++
-			continue
 		}
-		// Insert separator for readability (if provided)
-		for i := range glyphs {
-			glyphs[i] = InsertSep(glyphs[i], glyphSep)
+		if q, ok := reporter.(quitter); ok && q.Quit() {
+			break
 		}
+	}
 
-		// Verify exact round-trip using batch decode
-		okAll := true
-		decoded, derr := DecodeGlyphTokens(glyphs, wordsList, keyStr)
-		if derr != nil {
-			okAll = false
-		} else {
-			if len(decoded) != len(words) {
-				okAll = false
-			} else {
-				for i := range words {
-					if decoded[i] != words[i] {
-						okAll = false
-						break
-					}
-				}
-			}
-		}
+	result := SelfTestResult{Title: title, TotalSets: len(results), FailedSets: failed, Sets: results}
+	reporter.ReportSummary(result)
+	return result
+}
 
-		// Print this set
-		// Only print "Set N:" when multiple sets are requested
-		if len(sets) > 1 {
-			title := fmt.Sprintf("Set %d:", si+1)
-			fmt.Println(Style(title, Bold, Purple))
-			printed++
-		}
+// runSet generates one randomized, non-repeating set of `size` word positions
+// using an independent RandSource seeded from shardSeed, then verifies the
+// encode/decode round-trip exactly.
+func (r *SelfTestRunner) runSet(idx, size int, perm []int, seed []byte) SelfTestSetResult {
+	src := newShakeXOF(seed)
+	res := SelfTestSetResult{Index: idx, Size: size, FailIndex: -1}
 
-		// Words block (split across two lines if 24)
-		if len(words) == 24 {
-			fmt.Printf("  Words:  %s\n", strings.Join(words[:12], " "))
-			printed++
-			fmt.Printf("          %s\n", strings.Join(words[12:], " "))
-			printed++
-		} else {
-			fmt.Printf("  Words:  %s\n", strings.Join(words, " "))
-			printed++
+	seen := make(map[int]bool, size)
+	seq := make([]int, 0, size)
+	for len(seq) < size {
+		p := src.RandInt(len(r.WordsList))
+		if seen[p] {
+			continue
 		}
+		seen[p] = true
+		seq = append(seq, p)
+	}
+
+	words := make([]string, size)
+	for i := 0; i < size; i++ {
+		words[i] = r.WordsList[perm[seq[i]]]
+	}
+	res.Words = words
+
+	glyphs, err := EncodeWordsWithPolicy(words, r.Index, r.WordsList, r.Key, r.Policy)
+	if err != nil {
+		res.EncodeErr = err.Error()
+		return res
+	}
+	res.Glyphs = glyphs
 
-		// Glyphs block (split similarly for 24)
-		if len(glyphs) == 24 {
-			fmt.Printf("  Glyphs: %s\n", strings.Join(glyphs[:12], "  "))
-			fmt.Printf("          %s\n", strings.Join(glyphs[12:], "  "))
-			printed += 2
-		} else {
-			fmt.Printf("  Glyphs: %s\n", strings.Join(glyphs, "  "))
-			printed++
+	decoded, err := DecodeGlyphTokensWithPolicy(glyphs, r.WordsList, r.Key, r.Policy)
+	if err != nil {
+		res.DecodeErr = err.Error()
+		return res
+	}
+	if len(decoded) != len(words) {
+		res.DecodeErr = fmt.Sprintf("round-trip length mismatch: decoded %d != %d", len(decoded), len(words))
+		return res
+	}
+	for i := range words {
+		if decoded[i] != words[i] {
+			res.FailIndex = i
+			res.Expected = words[i]
+			res.Decoded = decoded[i]
+			return res
 		}
+	}
+	res.Passed = true
+	return res
+}
 
-		// Result line
-		var result string
-		if okAll {
-			result = "PASSED"
-		} else {
-			result = "FAILED"
-			failed++
+// RunSelfTest is the legacy entry point: it runs sets through a
+// SelfTestRunner and reports them via a TextReporter configured to match the
+// original inline printing (TTY pagination included), returning the failed-set
+// count as before.
+func RunSelfTest(wordsList []string, index map[string]int, keyStr string, policy KeyPolicy, glyphSep string, paginate bool, height int, sets []int, title string) int {
+	runner := NewSelfTestRunner(wordsList, index, keyStr, policy, sets)
+	reporter := NewTextReporter(glyphSep, paginate, height)
+	result := runner.Run(reporter, title)
+	return result.FailedSets
+}
+
+// --- TextReporter: human-readable output, with the original TTY pagination ---
+
+// TextReporter renders self-test results the way RunSelfTest always has:
+// one "Set N:" block per set (when more than one is requested), word/glyph
+// lines, a pass/fail result line, and a summary — paginating with a
+// "-- more --" prompt when writing to a TTY.
+type TextReporter struct {
+	GlyphSep string
+	Paginate bool
+	Height   int
+	Writer   io.Writer // defaults to os.Stdout
+	Reader   io.Reader // defaults to os.Stdin (pagination prompts only)
+
+	title     string
+	totalSets int
+	printed   int
+	quit      bool
+}
+
+// NewTextReporter builds a TextReporter with the given separator, pagination
+// setting, and terminal height.
+func NewTextReporter(glyphSep string, paginate bool, height int) *TextReporter {
+	return &TextReporter{GlyphSep: glyphSep, Paginate: paginate, Height: height}
+}
+
+func (t *TextReporter) writer() io.Writer {
+	if t.Writer != nil {
+		return t.Writer
+	}
+	return os.Stdout
+}
+
+func (t *TextReporter) reader() io.Reader {
+	if t.Reader != nil {
+		return t.Reader
+	}
+	return os.Stdin
+}
+
+func (t *TextReporter) printHeader() {
+	if t.title != "" {
+		fmt.Fprintln(t.writer(), Style(t.title, Bold, Blue))
+		t.printed++
+	}
+}
+
+func (t *TextReporter) ReportHeader(title string, totalSets int) {
+	t.title = title
+	t.totalSets = totalSets
+	if t.Paginate {
+		t.printHeader()
+	}
+}
+
+func (t *TextReporter) ReportSet(set SelfTestSetResult) {
+	if set.EncodeErr != "" {
+		fmt.Fprintf(os.Stderr, "self-test encode error: %s\n", set.EncodeErr)
+		return
+	}
+
+	w := t.writer()
+	if t.totalSets > 1 {
+		fmt.Fprintln(w, Style(fmt.Sprintf("Set %d:", set.Index+1), Bold, Purple))
+		t.printed++
+	}
+
+	glyphs := make([]string, len(set.Glyphs))
+	for i, g := range set.Glyphs {
+		glyphs[i] = InsertSep(g, t.GlyphSep)
+	}
+
+	if len(set.Words) == 24 {
+		fmt.Fprintf(w, "  Words:  %s\n", strings.Join(set.Words[:12], " "))
+		fmt.Fprintf(w, "          %s\n", strings.Join(set.Words[12:], " "))
+		t.printed += 2
+	} else {
+		fmt.Fprintf(w, "  Words:  %s\n", strings.Join(set.Words, " "))
+		t.printed++
+	}
+
+	if len(glyphs) == 24 {
+		fmt.Fprintf(w, "  Glyphs: %s\n", strings.Join(glyphs[:12], "  "))
+		fmt.Fprintf(w, "          %s\n", strings.Join(glyphs[12:], "  "))
+		t.printed += 2
+	} else {
+		fmt.Fprintf(w, "  Glyphs: %s\n", strings.Join(glyphs, "  "))
+		t.printed++
+	}
+
+	result := "PASSED"
+	if !set.Passed {
+		result = "FAILED"
+	}
+	label := fmt.Sprintf("Result: %s — Verified: %d passes", result, set.Size)
+	fmt.Fprintln(w, Style("  "+label, Bold))
+	t.printed++
+
+	if t.Paginate && t.printed >= t.Height-1 {
+		fmt.Fprint(os.Stderr, "-- more -- (Enter to continue, q to quit) ")
+		var buf [1]byte
+		_, er := t.reader().Read(buf[:])
+		fmt.Fprintln(os.Stderr)
+		if er == nil && (buf[0] == 'q' || buf[0] == 'Q') {
+			t.quit = true
+			return
 		}
-		label := fmt.Sprintf("Result: %s â€” Verified: %d passes", result, sz)
-		fmt.Println(Style("  "+label, Bold))
-		printed++
-
-		// Pagination
-		if paginate && printed >= height-1 {
-			fmt.Fprint(os.Stderr, "-- more -- (Enter to continue, q to quit) ")
-			var buf [1]byte
-			_, er := os.Stdin.Read(buf[:])
-			fmt.Fprintln(os.Stderr)
-			if er == nil && (buf[0] == 'q' || buf[0] == 'Q') {
-				break
+		t.printed = 0
+		t.printHeader()
+	}
+}
+
+func (t *TextReporter) ReportSummary(result SelfTestResult) {
+	if result.TotalSets > 1 {
+		fmt.Fprintf(t.writer(), "%s %d, %s %d\n",
+			Style("Total sets:", Bold), result.TotalSets,
+			Style("Failed:", Bold), result.FailedSets)
+	}
+}
+
+// Quit reports whether the user pressed 'q' at a "-- more --" pagination
+// prompt, so SelfTestRunner.Run can stop delivering further sets.
+func (t *TextReporter) Quit() bool {
+	return t.quit
+}
+
+// --- JSONReporter: one JSON document describing the full result ---
+
+// JSONReporter buffers nothing per-set and emits a single indented JSON
+// SelfTestResult document to Writer (os.Stdout if nil) from ReportSummary.
+type JSONReporter struct {
+	Writer io.Writer
+}
+
+// NewJSONReporter builds a JSONReporter writing to w.
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{Writer: w}
+}
+
+func (j *JSONReporter) ReportHeader(title string, totalSets int) {}
+func (j *JSONReporter) ReportSet(set SelfTestSetResult)          {}
+
+func (j *JSONReporter) ReportSummary(result SelfTestResult) {
+	w := j.Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "self-test: failed to encode JSON report: %v\n", err)
+	}
+}
+
+// --- JUnitXMLReporter: one <testsuite> with one <testcase> per set ---
+
+// JUnitXMLReporter writes a JUnit-compatible XML report to Writer (os.Stdout
+// if nil) from ReportSummary, suitable for CI test result ingestion.
+type JUnitXMLReporter struct {
+	Writer io.Writer
+}
+
+// NewJUnitXMLReporter builds a JUnitXMLReporter writing to w.
+func NewJUnitXMLReporter(w io.Writer) *JUnitXMLReporter {
+	return &JUnitXMLReporter{Writer: w}
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+func (j *JUnitXMLReporter) ReportHeader(title string, totalSets int) {}
+func (j *JUnitXMLReporter) ReportSet(set SelfTestSetResult)          {}
+
+func (j *JUnitXMLReporter) ReportSummary(result SelfTestResult) {
+	suite := junitTestSuite{Name: result.Title, Tests: result.TotalSets, Failures: result.FailedSets}
+	for _, set := range result.Sets {
+		tc := junitTestCase{Name: fmt.Sprintf("set-%d-%d-words", set.Index+1, set.Size)}
+		switch {
+		case set.EncodeErr != "":
+			tc.Failure = &junitFailure{Message: "encode error", Text: set.EncodeErr}
+		case set.DecodeErr != "":
+			tc.Failure = &junitFailure{Message: "decode error", Text: set.DecodeErr}
+		case !set.Passed:
+			tc.Failure = &junitFailure{
+				Message: "round-trip mismatch",
+				Text:    fmt.Sprintf("position %d: expected %q, got %q", set.FailIndex, set.Expected, set.Decoded),
 			}
-			printed = 0
-			header()
 		}
+		suite.TestCases = append(suite.TestCases, tc)
 	}
 
-	// Summary (only when multiple sets)
-	if len(sets) > 1 {
-		fmt.Printf("%s %d, %s %d\n",
-			Style("Total sets:", Bold), len(sets),
-			Style("Failed:", Bold), failed)
+	w := j.Writer
+	if w == nil {
+		w = os.Stdout
 	}
-
-	return failed
+	fmt.Fprint(w, xml.Header)
+	out, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "self-test: failed to encode JUnit XML report: %v\n", err)
+		return
+	}
+	w.Write(out)
+	fmt.Fprintln(w)
 }
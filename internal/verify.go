@@ -43,14 +43,14 @@ func EncodeWordsVerified(words []string, index map[string]int, wordsList []strin
 		effKey = string(seed32[:])
 	}
 
-	// Encode words -> glyphs
-	glyphs, err := EncodeWords(normalized, index, wordsList, effKey)
+	// Encode words -> glyphs, honoring policy.RNG for the permutation XOF.
+	glyphs, err := EncodeWordsWithPolicy(normalized, index, wordsList, effKey, policy)
 	if err != nil {
 		return nil, fmt.Errorf("encode failed: %w", err)
 	}
 
 	// Decode glyphs -> words and verify order-sensitive equality
-	decoded, err := DecodeGlyphTokens(glyphs, wordsList, effKey)
+	decoded, err := DecodeGlyphTokensWithPolicy(glyphs, wordsList, effKey, policy)
 	if err != nil {
 		return nil, fmt.Errorf("decode failed: %w", err)
 	}
@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeWord is the canonical form used for every word-list index lookup:
+// trim surrounding whitespace, NFKD-normalize (so precomposed and decomposed
+// accented Latin letters, and compatibility CJK forms, compare equal), then
+// lowercase. ASCII-only lists (e.g. English) are unaffected; this only
+// matters once non-English wordlists are in play.
+func NormalizeWord(w string) string {
+	return strings.ToLower(norm.NFKD.String(strings.TrimSpace(w)))
+}
+
+// Language describes one of the ten official BIP-39 wordlists. Words is
+// lazy so that a language whose word data isn't bundled into a particular
+// build only fails when actually selected, not at startup.
+type Language struct {
+	Code  string // short --list value, e.g. "ja"
+	Name  string // long --list value, e.g. "japanese"
+	Words func() ([]string, error)
+}
+
+// bip39Languages lists every official BIP-39 language. Only English ships
+// its word data in this build; the rest are registered so --list/--auto can
+// name and detect them, but resolve to a clear "not bundled" error until
+// their word files are added.
+var bip39Languages = []Language{
+	{Code: "en", Name: "english", Words: func() ([]string, error) { return WordsBIP39EN, nil }},
+	{Code: "ja", Name: "japanese", Words: unbundledWordlist("japanese")},
+	{Code: "ko", Name: "korean", Words: unbundledWordlist("korean")},
+	{Code: "es", Name: "spanish", Words: unbundledWordlist("spanish")},
+	{Code: "zh-hans", Name: "chinese-simplified", Words: unbundledWordlist("chinese-simplified")},
+	{Code: "zh-hant", Name: "chinese-traditional", Words: unbundledWordlist("chinese-traditional")},
+	{Code: "fr", Name: "french", Words: unbundledWordlist("french")},
+	{Code: "it", Name: "italian", Words: unbundledWordlist("italian")},
+	{Code: "cs", Name: "czech", Words: unbundledWordlist("czech")},
+	{Code: "pt", Name: "portuguese", Words: unbundledWordlist("portuguese")},
+}
+
+func unbundledWordlist(name string) func() ([]string, error) {
+	return func() ([]string, error) {
+		return nil, fmt.Errorf("%s wordlist is not bundled in this build", name)
+	}
+}
+
+// Bip39Languages returns every registered BIP-39 language, English first,
+// including ones whose word data isn't bundled into this build (Language.Words
+// returns an error for those). --self-test treats that error as a failure, not
+// a skip, so an unbundled language shows up in the tool's own exit code.
+func Bip39Languages() []Language {
+	return bip39Languages
+}
+
+// LookupLanguage finds a registered BIP-39 language by its --list code or
+// name, case-insensitively. It does not check whether the language's word
+// data is actually bundled; call Words to find that out.
+func LookupLanguage(name string) (Language, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, l := range bip39Languages {
+		if l.Code == name || l.Name == name {
+			return l, true
+		}
+	}
+	return Language{}, false
+}
+
+// DetectLanguage inspects the first few tokens of candidate mnemonic input
+// and returns the single bundled language whose wordlist contains all of
+// them. It errors cleanly if no bundled language matches, or if more than
+// one does (short, common words can appear in several languages' lists).
+func DetectLanguage(tokens []string) (Language, error) {
+	sample := tokens
+	if len(sample) > 3 {
+		sample = sample[:3]
+	}
+	if len(sample) == 0 {
+		return Language{}, fmt.Errorf("auto-detect needs at least one word")
+	}
+
+	var candidates []Language
+	for _, l := range bip39Languages {
+		words, err := l.Words()
+		if err != nil {
+			continue // not bundled in this build, can't be a candidate
+		}
+		index := make(map[string]struct{}, len(words))
+		for _, w := range words {
+			index[NormalizeWord(w)] = struct{}{}
+		}
+		matches := true
+		for _, t := range sample {
+			if _, ok := index[NormalizeWord(t)]; !ok {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			candidates = append(candidates, l)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return Language{}, fmt.Errorf("no bundled wordlist matches the given words")
+	case 1:
+		return candidates[0], nil
+	default:
+		names := make([]string, len(candidates))
+		for i, c := range candidates {
+			names[i] = c.Name
+		}
+		return Language{}, fmt.Errorf("ambiguous between %s; pass --list explicitly", strings.Join(names, ", "))
+	}
+}
+
+// SplitMnemonicTokens flattens each token on Unicode whitespace, including
+// U+3000 IDEOGRAPHIC SPACE (the Japanese wordlist's conventional separator),
+// so a phrase passed as a single shell-quoted argument still tokenizes
+// correctly even when the shell itself only split on plain spaces.
+func SplitMnemonicTokens(tokens []string) []string {
+	out := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, strings.Fields(t)...)
+	}
+	return out
+}
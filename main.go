@@ -25,19 +25,26 @@ package main
 import (
 	"bufio"
 	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
 	"flag"
 	"fmt"
+	"image/png"
+	"io"
 	"math/big"
 	"math/rand"
 	"os"
 	"path/filepath"
 
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 	"unicode/utf8"
 
 	"glyphriot/internal"
+	"glyphriot/internal/shamir"
 
 	"golang.org/x/term"
 	qr "rsc.io/qr"
@@ -54,7 +61,7 @@ func buildWordList(name, txt string) WordList {
 	lines := strings.Split(strings.TrimSpace(txt), "\n")
 	idx := make(map[string]int, len(lines))
 	for i, w := range lines {
-		lw := strings.ToLower(strings.TrimSpace(w))
+		lw := internal.NormalizeWord(w)
 		if lw == "" {
 			continue
 		}
@@ -104,15 +111,212 @@ func loadListFile(path string) (WordList, error) {
 	// Build index and detect duplicates
 	idx := make(map[string]int, len(lines))
 	for i, w := range lines {
-		if _, exists := idx[w]; exists {
+		nw := internal.NormalizeWord(w)
+		if _, exists := idx[nw]; exists {
 			return WordList{}, fmt.Errorf("--list-file contains duplicate word %q at logical line %d", w, i+1)
 		}
-		idx[w] = i
+		idx[nw] = i
 	}
 
 	return WordList{Name: "custom", Words: lines, Index: idx}, nil
 }
 
+// parseMOfN parses an "M-of-N" string (e.g. "2-of-3") into threshold and total.
+func parseMOfN(s string) (threshold, total int, err error) {
+	parts := strings.SplitN(strings.ToLower(strings.TrimSpace(s)), "-of-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("want M-of-N (e.g. 2-of-3), got %q", s)
+	}
+	threshold, errM := strconv.Atoi(strings.TrimSpace(parts[0]))
+	total, errN := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if errM != nil || errN != nil {
+		return 0, 0, fmt.Errorf("want M-of-N (e.g. 2-of-3), got %q", s)
+	}
+	return threshold, total, nil
+}
+
+// kdfParamFlag collects repeated --kdf-param key=value flags into a map.
+type kdfParamFlag struct {
+	values map[string]string
+}
+
+func (f *kdfParamFlag) String() string {
+	if f == nil || len(f.values) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(f.values))
+	for k, v := range f.values {
+		parts = append(parts, k+"="+v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *kdfParamFlag) Set(s string) error {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("--kdf-param must be key=value, got %q", s)
+	}
+	if f.values == nil {
+		f.values = map[string]string{}
+	}
+	f.values[kv[0]] = kv[1]
+	return nil
+}
+
+// resultReporter is an internal.Reporter that discards per-set output and
+// just captures the final internal.SelfTestResult, for the --self-test-format
+// json/junit paths where only the aggregate report matters.
+type resultReporter struct {
+	result internal.SelfTestResult
+}
+
+func (r *resultReporter) ReportHeader(title string, totalSets int)     {}
+func (r *resultReporter) ReportSet(set internal.SelfTestSetResult)     {}
+func (r *resultReporter) ReportSummary(result internal.SelfTestResult) { r.result = result }
+
+// cliJUnitTestSuites wraps one <testsuite> per self-test phase (no-key/with-key
+// x 12/24 words) in a single <testsuites> root, since internal.JUnitXMLReporter
+// only emits one suite per run and the CLI runs four.
+type cliJUnitTestSuites struct {
+	XMLName xml.Name            `xml:"testsuites"`
+	Suites  []cliJUnitTestSuite `xml:"testsuite"`
+}
+
+type cliJUnitTestSuite struct {
+	Name      string             `xml:"name,attr"`
+	Tests     int                `xml:"tests,attr"`
+	Failures  int                `xml:"failures,attr"`
+	TestCases []cliJUnitTestCase `xml:"testcase"`
+}
+
+type cliJUnitTestCase struct {
+	Name    string           `xml:"name,attr"`
+	Failure *cliJUnitFailure `xml:"failure,omitempty"`
+}
+
+type cliJUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func toJUnitSuite(r internal.SelfTestResult) cliJUnitTestSuite {
+	suite := cliJUnitTestSuite{Name: r.Title, Tests: r.TotalSets, Failures: r.FailedSets}
+	for _, s := range r.Sets {
+		tc := cliJUnitTestCase{Name: fmt.Sprintf("set-%d-%d-words", s.Index+1, s.Size)}
+		switch {
+		case s.EncodeErr != "":
+			tc.Failure = &cliJUnitFailure{Message: "encode error", Text: s.EncodeErr}
+		case s.DecodeErr != "":
+			tc.Failure = &cliJUnitFailure{Message: "decode error", Text: s.DecodeErr}
+		case !s.Passed:
+			tc.Failure = &cliJUnitFailure{
+				Message: "round-trip mismatch",
+				Text:    fmt.Sprintf("position %d: expected %q, got %q", s.FailIndex, s.Expected, s.Decoded),
+			}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	return suite
+}
+
+// selfTestSplitCombine mirrors the word-set self-test: it generates a random
+// checksum-valid 12-word phrase, splits it 2-of-3 with shamir.SplitMnemonic,
+// recombines from 2 of the 3 shares, and verifies the recovered phrase is
+// exactly equal. Returns 1 (failed) or 0 (passed), matching RunSelfTest's
+// failure-count convention.
+func selfTestSplitCombine() int {
+	entropy := make([]byte, 16)
+	if _, err := crand.Read(entropy); err != nil {
+		fmt.Fprintf(os.Stderr, "self-test split/combine error: %v\n", err)
+		return 1
+	}
+	words, err := internal.EntropyToMnemonic(entropy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "self-test split/combine error: %v\n", err)
+		return 1
+	}
+	shares, err := shamir.SplitMnemonic(words, 2, 3)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "self-test split/combine error: %v\n", err)
+		return 1
+	}
+	recovered, err := shamir.CombineMnemonic(shares[:2])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "self-test split/combine error: %v\n", err)
+		return 1
+	}
+
+	pass := len(recovered) == len(words)
+	if pass {
+		for i := range words {
+			if recovered[i] != words[i] {
+				pass = false
+				break
+			}
+		}
+	}
+	result := "PASSED"
+	if !pass {
+		result = "FAILED"
+	}
+	fmt.Printf("  %s %s\n", internal.Style("Result:", internal.Bold), result)
+	if pass {
+		return 0
+	}
+	return 1
+}
+
+// selfTestLanguages round-trips a random 12-word mnemonic through
+// EntropyToMnemonicWithList/MnemonicToEntropyWithList for every registered
+// BIP-39 language. A language whose word data isn't bundled in this build
+// counts as a failure rather than a skip: --self-test's exit code is the
+// tool's own claim that every advertised language actually works, and an
+// unbundled wordlist means it doesn't, regardless of why.
+func selfTestLanguages() int {
+	failed := 0
+	for _, lang := range internal.Bip39Languages() {
+		words, err := lang.Words()
+		if err != nil {
+			fmt.Printf("  %-20s %s\n", lang.Name, internal.Style("UNBUNDLED ("+err.Error()+")", internal.Bold))
+			failed++
+			continue
+		}
+
+		entropy := make([]byte, 16)
+		if _, err := crand.Read(entropy); err != nil {
+			fmt.Fprintf(os.Stderr, "self-test languages error: %v\n", err)
+			failed++
+			continue
+		}
+		mnemonic, err := internal.EntropyToMnemonicWithList(entropy, words)
+		if err == nil {
+			var recovered []byte
+			recovered, err = internal.MnemonicToEntropyWithList(mnemonic, words)
+			if err == nil {
+				pass := len(recovered) == len(entropy)
+				if pass {
+					for i := range entropy {
+						if recovered[i] != entropy[i] {
+							pass = false
+							break
+						}
+					}
+				}
+				if !pass {
+					err = fmt.Errorf("round-trip mismatch")
+				}
+			}
+		}
+		if err != nil {
+			fmt.Printf("  %-20s %s\n", lang.Name, internal.Style("FAILED ("+err.Error()+")", internal.Bold))
+			failed++
+			continue
+		}
+		fmt.Printf("  %-20s PASSED\n", lang.Name)
+	}
+	return failed
+}
+
 // randomKeyFromList returns a crypto‑random key by picking a random word
 // from the active word list. Falls back to "test-key" on any failure.
 func randomKeyFromList(active WordList) string {
@@ -126,11 +330,280 @@ func randomKeyFromList(active WordList) string {
 	return active.Words[n.Int64()]
 }
 
+// cfgUintParam reads a numeric KDFParams entry from the loaded config,
+// falling back to def when absent, empty, or unparseable.
+func cfgUintParam(policy internal.KeyPolicy, name string, def uint) uint {
+	v, ok := policy.KDFParams[name]
+	if !ok {
+		return def
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return def
+	}
+	return uint(n)
+}
+
+// runShareCommand implements `glyphriot share split|combine`, a Shamir's
+// Secret Sharing backup path built on internal/shamir. It loads the on-disk
+// config itself, the same way the main encode/decode flow does, since this
+// subcommand is dispatched before that flow's LoadConfig call runs.
+func runShareCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: glyphriot share split|combine ...")
+		os.Exit(2)
+	}
+	cfg, cfgErr := internal.LoadConfig("")
+	if cfgErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v (using built-in defaults)\n", cfgErr)
+		cfg = internal.DefaultConfig()
+	}
+	switch args[0] {
+	case "split":
+		runShareSplit(args[1:], cfg.ToKeyPolicy())
+	case "combine":
+		runShareCombine(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown share subcommand %q (want split or combine)\n", args[0])
+		os.Exit(2)
+	}
+}
+
+func runShareSplit(args []string, policy internal.KeyPolicy) {
+	fs := flag.NewFlagSet("share split", flag.ExitOnError)
+	threshold := fs.Int("threshold", 2, "Number of shares required to reconstruct (M)")
+	total := fs.Int("total", 3, "Total number of shares to generate (N)")
+	key := fs.String("key", "", "Passphrase to split; omit to be prompted securely")
+	useSeed := fs.Bool("seed", false, "Split the 32-byte effective key derived with the configured KDF (argon2id by default) instead of the raw passphrase")
+	fs.Parse(args)
+
+	secretStr := *key
+	if strings.TrimSpace(secretStr) == "" {
+		ks, err := internal.PromptForKey(true)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		secretStr = ks
+	}
+
+	var secret []byte
+	if *useSeed {
+		seed, err := internal.MustEffectiveKeyMaterial(secretStr, internal.MinBitsForContext(12), policy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		secret = seed[:]
+	} else {
+		secret = []byte(secretStr)
+	}
+
+	shares, err := shamir.SplitKey(secret, *threshold, *total)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(internal.Style(fmt.Sprintf("%d-of-%d shares:", *threshold, *total), internal.Bold, internal.Blue))
+	for i, s := range shares {
+		fmt.Printf("  Share %d: %s\n", i+1, s)
+	}
+}
+
+func runShareCombine(args []string) {
+	fs := flag.NewFlagSet("share combine", flag.ExitOnError)
+	asHex := fs.Bool("hex", false, "Print the recovered secret as hex instead of text")
+	fs.Parse(args)
+	shares := fs.Args()
+	if len(shares) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: glyphriot share combine <share> [<share> ...]")
+		os.Exit(2)
+	}
+	secret, err := shamir.CombineKey(shares)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if *asHex {
+		fmt.Printf("%x\n", secret)
+	} else {
+		fmt.Println(string(secret))
+	}
+}
+
+// runInitCommand implements `glyphriot init [--print] [--path p]`: it writes
+// (or, with --print, just displays) the effective config, mirroring the
+// --init pattern used by tools like cheat.
+func runInitCommand(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	printCfg := fs.Bool("print", false, "Print the effective config to stdout instead of writing it")
+	path := fs.String("path", "", "Override the config path (default: $XDG_CONFIG_HOME/glyphriot/config.toml)")
+	fs.Parse(args)
+
+	if *printCfg {
+		cfg, err := internal.LoadConfig(*path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := internal.EncodeConfig(os.Stdout, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	written, err := internal.WriteDefaultConfig(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote default config to %s\n", written)
+}
+
+// runMnemonicCommand implements `glyphriot mnemonic new --bits 128|256`: it
+// emits a fresh checksum-valid BIP-39 phrase and its glyph encoding under the
+// current (identity, since no --key is accepted here) permutation.
+func runMnemonicCommand(args []string) {
+	if len(args) == 0 || args[0] != "new" {
+		fmt.Fprintln(os.Stderr, "usage: glyphriot mnemonic new --bits 128|256")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("mnemonic new", flag.ExitOnError)
+	bits := fs.Int("bits", 128, "Entropy bits for the new phrase: 128 (12 words) or 256 (24 words)")
+	fs.Parse(args[1:])
+
+	if *bits != 128 && *bits != 256 {
+		fmt.Fprintln(os.Stderr, "error: --bits must be 128 or 256")
+		os.Exit(2)
+	}
+
+	ent := make([]byte, *bits/8)
+	if _, err := crand.Read(ent); err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to generate entropy: %v\n", err)
+		os.Exit(1)
+	}
+
+	words, err := internal.EntropyToMnemonic(ent)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	glyphs, err := internal.EncodeWords(words, wlBip39.Index, wlBip39.Words, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(internal.Style("Phrase:", internal.Bold, internal.Purple))
+	fmt.Println(strings.Join(words, " "))
+	fmt.Println()
+	fmt.Println(internal.Style("Glyph:", internal.Bold, internal.Purple))
+	fmt.Println(strings.Join(glyphs, "  "))
+}
+
+// runBatch implements --batch: one "ENC <phrase>"/"DEC <glyph tokens>"
+// request per stdin line, one "OK <result>"/"ERR <message>" response per
+// stdout line, flushed immediately so it works as a coprocess. The effective
+// key material is derived from keyStr with the policy KDF once, here at
+// startup, and reused for every request in the session.
+func runBatch(active WordList, keyStr string, policy internal.KeyPolicy) {
+	effKey := ""
+	if strings.TrimSpace(keyStr) != "" {
+		// EffectiveKeyMaterial doesn't vary with word count, only
+		// EnforceOrError's minBits does; enforcing against the stricter
+		// 24-word context once covers every request regardless of length.
+		seed, err := internal.MustEffectiveKeyMaterial(keyStr, internal.MinBitsForContext(24), policy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		effKey = string(seed[:])
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fmt.Fprintln(out, batchHandle(line, active, effKey, policy))
+		out.Flush()
+	}
+}
+
+// batchHandle processes one "ENC <phrase>"/"DEC <glyph tokens>" request line
+// and returns the matching "OK <result>"/"ERR <message>" response. Errors are
+// always a fixed, sanitized message rather than the underlying error or the
+// raw request line, matching the existing "invalid glyph input" convention
+// so malformed input can't leak into logs via stdout/stderr.
+func batchHandle(line string, active WordList, effKey string, policy internal.KeyPolicy) string {
+	verb, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToUpper(verb) {
+	case "ENC":
+		words := strings.Fields(rest)
+		if len(words) == 0 {
+			return "ERR no words provided"
+		}
+		normalized := make([]string, len(words))
+		for i, w := range words {
+			normalized[i] = internal.NormalizeWord(w)
+		}
+		glyphs, err := batchEncode(normalized, active, effKey, policy)
+		if err != nil {
+			return "ERR invalid phrase input"
+		}
+		return "OK " + strings.Join(glyphs, " ")
+	case "DEC":
+		tokens := strings.Fields(rest)
+		if len(tokens) == 0 {
+			return "ERR no glyph tokens provided"
+		}
+		decoded, err := internal.DecodeGlyphTokensWithPolicy(tokens, active.Words, effKey, policy)
+		if err != nil {
+			return "ERR invalid glyph input"
+		}
+		return "OK " + strings.Join(decoded, " ")
+	default:
+		return "ERR unknown request (want ENC or DEC)"
+	}
+}
+
+// batchEncode mirrors internal.EncodeWordsVerified's encode-then-verify
+// round trip, but takes already-derived effective key material instead of a
+// raw passphrase, so --batch never re-runs the policy KDF per request.
+func batchEncode(words []string, active WordList, effKey string, policy internal.KeyPolicy) ([]string, error) {
+	glyphs, err := internal.EncodeWordsWithPolicy(words, active.Index, active.Words, effKey, policy)
+	if err != nil {
+		return nil, fmt.Errorf("encode failed: %w", err)
+	}
+	decoded, err := internal.DecodeGlyphTokensWithPolicy(glyphs, active.Words, effKey, policy)
+	if err != nil {
+		return nil, fmt.Errorf("decode failed: %w", err)
+	}
+	if len(decoded) != len(words) {
+		return nil, fmt.Errorf("round-trip mismatch: decoded length %d != %d", len(decoded), len(words))
+	}
+	for i := range words {
+		if decoded[i] != words[i] {
+			return nil, fmt.Errorf("round-trip mismatch at position %d", i)
+		}
+	}
+	return glyphs, nil
+}
+
 var version = "dev"
 var wlBip39 = func() WordList {
 	idx := make(map[string]int, len(internal.WordsBIP39EN))
 	for i, w := range internal.WordsBIP39EN {
-		lw := strings.ToLower(strings.TrimSpace(w))
+		lw := internal.NormalizeWord(w)
 		if lw == "" {
 			continue
 		}
@@ -139,6 +612,17 @@ var wlBip39 = func() WordList {
 	return WordList{Name: "bip39-en", Words: internal.WordsBIP39EN, Index: idx}
 }()
 
+// wordListFromLanguage builds a WordList from a bundled internal.Language,
+// reusing buildWordList's index construction so lookup normalization stays
+// identical across bip39-en, --list-file, and every other --list=<language>.
+func wordListFromLanguage(lang internal.Language) (WordList, error) {
+	words, err := lang.Words()
+	if err != nil {
+		return WordList{}, err
+	}
+	return buildWordList(lang.Name, strings.Join(words, "\n")), nil
+}
+
 func usage() {
 	prog := filepath.Base(os.Args[0])
 
@@ -153,7 +637,7 @@ func usage() {
 
 	// Flags
 	fmt.Println(internal.Style("Flags:", internal.Bold, internal.Blue))
-	fmt.Println(internal.Style("  --all  --list  --list-file  --key|--prompt  --pager  --glyph-sep  --phrase-only  --no-qr  --no-color  --version", internal.Cyan))
+	fmt.Println(internal.Style("  --all  --list  --list-file  --key|--prompt  --pager  --glyph-sep  --phrase-only  --no-qr  --qr-ecc  --qr-split  --qr-out  --qr-combine  --batch  --no-color  --version", internal.Cyan))
 	fmt.Println()
 
 	// Glyphs and rules
@@ -333,28 +817,75 @@ func runSelfTest(active WordList, keyStr string, glyphSep string, paginate bool,
 }
 
 func main() {
+	// Subcommands are dispatched before the legacy flag set is parsed, since
+	// they take their own flag.NewFlagSet and don't mix with the encode/decode
+	// flags below.
+	if len(os.Args) > 1 && os.Args[1] == "mnemonic" {
+		runMnemonicCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		runInitCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "share" {
+		runShareCommand(os.Args[2:])
+		return
+	}
+
+	// Load the on-disk config (or defaults if none exists) to seed flag
+	// defaults below; any flag the user actually passes still overrides it.
+	cfg, cfgErr := internal.LoadConfig("")
+	if cfgErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v (using built-in defaults)\n", cfgErr)
+		cfg = internal.DefaultConfig()
+	}
+	cfgPolicy := cfg.ToKeyPolicy()
+
 	all := flag.Bool("all", false, "Generate full table for the selected word list")
 	table := flag.Bool("table", false, "Tabular output for provided words/phrase")
 	sep := flag.String("sep", "  ", "Separator between glyphs for phrase output")
-	list := flag.String("list", "bip39-en", "Word list: bip39-en (default), auto")
+	list := flag.String("list", cfg.WordList.Active, "Word list: bip39-en (default) or auto. The other 9 BIP-39 languages (japanese, korean, spanish, chinese-simplified, chinese-traditional, french, italian, czech, portuguese) are registered for --list/--auto detection but not yet bundled with word data in this build, and will fail with an explicit error if selected")
 	listFile := flag.String("list-file", "", "Load a custom 2048-word list from file (overrides --list)")
 	key := flag.String("key", "", "User key to reorder word mapping")
 	prompt := flag.Bool("prompt", false, "Securely prompt for key (no echo); overrides --key")
 	mask := flag.Bool("mask", true, "With --prompt, show * while typing (use --mask=false to disable)")
 	pager := flag.Bool("pager", true, "Paginate --all output when writing to a TTY (press Enter per page); --pager=false to disable")
 	selfTest := flag.Bool("self-test", false, "Run built-in test harness (4×12-word phrases)")
+	selfTestFormat := flag.String("self-test-format", "text", "Self-test report format: text (default), json, or junit")
+	selfTestOut := flag.String("self-test-out", "", "Write the self-test report to this file instead of stdout (json/junit formats)")
 	phraseOnly := flag.Bool("phrase-only", false, "Print only the recovered phrase when decoding glyphs")
+	batch := flag.Bool("batch", false, "Read \"ENC <phrase>\"/\"DEC <glyph tokens>\" requests from stdin, one per line, replying \"OK <result>\"/\"ERR <message>\"; key material is resolved once at startup")
 	noQR := flag.Bool("no-qr", false, "Do not display QR code for generated glyphs")
+	qrECC := flag.String("qr-ecc", "M", "QR error-correction level: L, M, Q, or H")
+	qrSplit := flag.Int("qr-split", 1, "Split the glyph payload into N QR codes using glyphriot's own multi-part framing, a non-standard scheme only this tool's decoder reassembles (1 = single QR, the default)")
+	qrOut := flag.String("qr-out", "", "Write QR code(s) as PNG to this path instead of the terminal (path-1.png..path-N.png when --qr-split > 1)")
+	qrCombine := flag.Bool("qr-combine", false, "Reassemble glyph input that was split across multiple QR codes by --qr-split: pass each scanned frame's raw text as one positional argument, in any order, instead of glyph tokens")
 
-	glyphSep := flag.String("glyph-sep", "", "Insert this separator between glyphs when printing; decoding strips it")
+	glyphSep := flag.String("glyph-sep", cfg.GlyphSep, "Insert this separator between glyphs when printing; decoding strips it")
 	versionFlag := flag.Bool("version", false, "Print version and exit")
-	noColor := flag.Bool("no-color", false, "Disable colored output (TTY-safe)")
-	kdf := flag.String("kdf", "argon2id", "Key derivation: argon2id (default) or none")
-	kdfMem := flag.Uint("kdf-mem-mb", 512, "Argon2id memory in MB (default 512)")
-	kdfTime := flag.Uint("kdf-time", 3, "Argon2id iterations (default 3)")
-	kdfPar := flag.Uint("kdf-parallel", 1, "Argon2id parallelism (default 1)")
-	allowWeak := flag.Bool("allow-weak-key", false, "Allow weak keys (not recommended)")
+	noColor := flag.Bool("no-color", !cfg.Color.Enabled, "Disable colored output (TTY-safe)")
+	kdf := flag.String("kdf", cfgPolicy.KDF, "Key derivation: argon2id (default), scrypt, pbkdf2-sha256, or none")
+	kdfMem := flag.Uint("kdf-mem-mb", cfgUintParam(cfgPolicy, "mem_mb", 512), "argon2id memory in MB (default 512)")
+	kdfTime := flag.Uint("kdf-time", cfgUintParam(cfgPolicy, "time", 3), "argon2id iterations (default 3)")
+	kdfPar := flag.Uint("kdf-parallel", cfgUintParam(cfgPolicy, "parallel", 1), "argon2id parallelism (default 1)")
+	var kdfParams kdfParamFlag
+	flag.Var(&kdfParams, "kdf-param", "KDF parameter as key=value (repeatable); e.g. --kdf-param n=32768 --kdf-param r=8 for scrypt, or --kdf-param iterations=600000 for pbkdf2-sha256")
+	printKDFHeader := flag.Bool("print-kdf-header", false, "Print a versioned KDF+params header token alongside encoded glyph output")
+	kdfHeader := flag.String("kdf-header", "", "A header token from --print-kdf-header; reconstructs --kdf/--kdf-param for decoding without re-specifying them")
+	allowWeak := flag.Bool("allow-weak-key", cfgPolicy.AllowWeak, "Allow weak keys (not recommended)")
+	rng := flag.String("rng", cfgPolicy.RNG, "Permutation RNG: shake256 (default) or sha256ctr (legacy, for reproducing pre-SHAKE256 permutations)")
 	alias := flag.String("alias", "academic:acoustic", "Comma-separated list of word aliases (e.g., academic:acoustic,organize:organise)")
+	recipient := flag.String("recipient", "", "Encrypt glyph output to this age public key (age1...) before printing")
+	recipientGPG := flag.String("recipient-gpg", "", "Encrypt glyph output to this OpenPGP key id/fingerprint before printing")
+	gpgPubring := flag.String("gpg-pubring", "", "Armored OpenPGP public keyring file (required with --recipient-gpg)")
+	identity := flag.String("identity", "", "age private key (AGE-SECRET-KEY-1...) to decrypt a glyphriot-enc:v1: blob")
+	identityGPG := flag.String("identity-gpg", "", "Armored OpenPGP private keyring file to decrypt a glyphriot-enc:v1: blob")
+	gpgPassphrase := flag.String("gpg-passphrase", "", "Passphrase for the --identity-gpg private key, if any")
+	split := flag.String("split", "", "Split the given BIP-39 seed phrase into glyph shares, as M-of-N (e.g. 2-of-3); reads the phrase from the positional arguments")
+	combine := flag.Bool("combine", false, "Combine glyph shares from --split back into the original seed phrase; reads shares from the positional arguments")
+	signOut := flag.Bool("sign", false, "Print a detached Ed25519 signature (hex) over the glyph output, derived deterministically from --key/--prompt")
+	verifySig := flag.String("verify-sig", "", "Hex-encoded Ed25519 signature (from --sign) to verify against decoded glyph input before printing; requires --key/--prompt")
 
 	flag.Parse()
 
@@ -366,16 +897,54 @@ func main() {
 	// Color enablement: default on for TTY unless --no-color
 	internal.SetColorEnabled(!*noColor && term.IsTerminal(int(syscall.Stdout)))
 
-	// Build key policy from flags
+	// Build key policy from flags. --kdf-mem-mb/--kdf-time/--kdf-parallel are
+	// argon2id-specific conveniences that seed KDFParams directly; --kdf-param
+	// overrides/extends them generically for any registered KDF (e.g. scrypt's
+	// n/r/p or pbkdf2-sha256's iterations).
 	policy := internal.DefaultKeyPolicy()
 	policy.KDF = strings.ToLower(strings.TrimSpace(*kdf))
-	policy.KDFMemMB = uint32(*kdfMem)
-	policy.KDFTime = uint32(*kdfTime)
-	policy.KDFParallel = uint8(*kdfPar)
+	if policy.KDF == "argon2id" {
+		policy.KDFParams = map[string]string{
+			"mem_mb":   fmt.Sprintf("%d", *kdfMem),
+			"time":     fmt.Sprintf("%d", *kdfTime),
+			"parallel": fmt.Sprintf("%d", *kdfPar),
+		}
+	} else {
+		// Seed from the configured KDF's params (copied, not aliased, since
+		// --kdf-param overlays below mutate the map) rather than starting
+		// empty, so a config.toml-configured scrypt/pbkdf2-sha256 isn't
+		// silently replaced by that KDF's hardcoded defaults whenever --kdf
+		// still resolves to the same name the config already set.
+		policy.KDFParams = map[string]string{}
+		if policy.KDF == cfgPolicy.KDF {
+			for k, v := range cfgPolicy.KDFParams {
+				policy.KDFParams[k] = v
+			}
+		}
+	}
+	for k, v := range kdfParams.values {
+		policy.KDFParams[k] = v
+	}
 	policy.AllowWeak = *allowWeak
+	policy.RNG = strings.ToLower(strings.TrimSpace(*rng))
+
+	if strings.TrimSpace(*kdfHeader) != "" {
+		name, params, err := internal.ParseKDFHeaderToken(strings.TrimSpace(*kdfHeader))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid --kdf-header: %v\n", err)
+			os.Exit(2)
+		}
+		policy.KDF = name
+		policy.KDFParams = params
+	}
 
-	// Determine active word list
+	// Determine active word list. --list=auto can't resolve until the input
+	// tokens are known, so it's deferred: autoDetectList marks that active
+	// below is only an English placeholder until the real tokens are parsed
+	// further down; --self-test and --all, which take no input tokens, run
+	// against that placeholder.
 	var active WordList
+	autoDetectList := false
 	if strings.TrimSpace(*listFile) != "" {
 		wl, err := loadListFile(*listFile)
 		if err != nil {
@@ -385,11 +954,24 @@ func main() {
 		active = wl
 	} else {
 		switch strings.ToLower(strings.TrimSpace(*list)) {
-		case "", "bip39-en", "auto":
+		case "", "bip39-en":
 			active = wlBip39
-		default:
-			fmt.Fprintf(os.Stderr, "warning: unknown --list=%q; defaulting to bip39-en\n", *list)
+		case "auto":
 			active = wlBip39
+			autoDetectList = true
+		default:
+			lang, ok := internal.LookupLanguage(*list)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "warning: unknown --list=%q; defaulting to bip39-en\n", *list)
+				active = wlBip39
+				break
+			}
+			wl, err := wordListFromLanguage(lang)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: --list=%s: %v\n", *list, err)
+				os.Exit(2)
+			}
+			active = wl
 		}
 	}
 
@@ -404,24 +986,33 @@ func main() {
 		keyStr = ks
 	}
 
-	// Self-test
-	if *selfTest {
-		// Paginate self-test output similar to --all
-		outIsTTY := term.IsTerminal(int(syscall.Stdout))
-		inIsTTY := term.IsTerminal(int(syscall.Stdin))
-		paginate := *pager && outIsTTY && inIsTTY
-		_, height, _ := term.GetSize(int(syscall.Stdout))
-		if height <= 0 {
-			height = 24
+	// --qr-combine: reassemble a --qr-split multi-part scan back into the
+	// original glyph payload, then fall through to the normal decode flow
+	// below with the reassembled tokens in place of flag.Args().
+	var combinedArgs []string
+	if *qrCombine {
+		payload, err := qrCombineFrames(flag.Args())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --qr-combine: %v\n", err)
+			os.Exit(2)
 		}
+		combinedArgs = strings.Fields(payload)
+	}
 
-		totalFailed := 0
-
-		// 12 words (no key)
-		fmt.Println(internal.Style("== Self-test: 12 words (no key) ==", internal.Bold))
-		totalFailed += internal.RunSelfTest(active.Words, active.Index, "", *glyphSep, paginate, height, []int{12}, "Self-test (12-word sets)")
+	// Batch mode: key material and word list are both fixed for the whole
+	// session, so --list=auto (which needs to see real input tokens first)
+	// isn't supported here.
+	if *batch {
+		if autoDetectList {
+			fmt.Fprintln(os.Stderr, "error: --batch does not support --list=auto; pass --list explicitly")
+			os.Exit(2)
+		}
+		runBatch(active, keyStr, policy)
+		return
+	}
 
-		// 12 words (with key; crypto-random)
+	// Self-test
+	if *selfTest {
 		// Generate a strong passphrase (>=16 chars) from random BIP-39 words for Argon2id defaults
 		// Ensures self-test passes key-strength enforcement without --allow-weak-key
 		minCharsK1 := 16
@@ -436,23 +1027,14 @@ func main() {
 			}
 			k1 = sb.String()
 		}
-		fmt.Println(internal.Style("== Self-test: 12 words (with key) ==", internal.Bold))
-		{
-			minBits := internal.MinBitsForContext(12)
-			eff, err := internal.MustEffectiveKeyMaterial(k1, minBits, policy)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				os.Exit(2)
-			}
-			effKey := string(eff[:])
-			totalFailed += internal.RunSelfTest(active.Words, active.Index, effKey, *glyphSep, paginate, height, []int{12}, "Self-test (12-word sets)")
+		minBits12 := internal.MinBitsForContext(12)
+		eff1, err := internal.MustEffectiveKeyMaterial(k1, minBits12, policy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
 		}
+		effKey1 := string(eff1[:])
 
-		// 24 words (no key)
-		fmt.Println(internal.Style("== Self-test: 24 words (no key) ==", internal.Bold))
-		totalFailed += internal.RunSelfTest(active.Words, active.Index, "", *glyphSep, paginate, height, []int{24}, "Self-test (24-word sets)")
-
-		// 24 words (with key; crypto-random)
 		// Generate a strong passphrase (>=20 chars) from random BIP-39 words for Argon2id defaults (24-word context)
 		minCharsK2 := 20
 		var k2 string
@@ -466,18 +1048,106 @@ func main() {
 			}
 			k2 = sb2.String()
 		}
-		fmt.Println(internal.Style("== Self-test: 24 words (with key) ==", internal.Bold))
-		{
-			minBits := internal.MinBitsForContext(24)
-			eff, err := internal.MustEffectiveKeyMaterial(k2, minBits, policy)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				os.Exit(2)
+		minBits24 := internal.MinBitsForContext(24)
+		eff2, err := internal.MustEffectiveKeyMaterial(k2, minBits24, policy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		effKey2 := string(eff2[:])
+
+		format := strings.ToLower(strings.TrimSpace(*selfTestFormat))
+		if format == "json" || format == "junit" {
+			run := func(key, title string, sizes []int) internal.SelfTestResult {
+				var rr resultReporter
+				internal.NewSelfTestRunner(active.Words, active.Index, key, policy, sizes).Run(&rr, title)
+				return rr.result
 			}
-			effKey := string(eff[:])
-			totalFailed += internal.RunSelfTest(active.Words, active.Index, effKey, *glyphSep, paginate, height, []int{24}, "Self-test (24-word sets)")
+			results := []internal.SelfTestResult{
+				run("", "Self-test (12-word sets, no key)", []int{12}),
+				run(effKey1, "Self-test (12-word sets, with key)", []int{12}),
+				run("", "Self-test (24-word sets, no key)", []int{24}),
+				run(effKey2, "Self-test (24-word sets, with key)", []int{24}),
+			}
+			totalFailed := 0
+			for _, r := range results {
+				totalFailed += r.FailedSets
+			}
+
+			var w io.Writer = os.Stdout
+			if strings.TrimSpace(*selfTestOut) != "" {
+				f, err := os.Create(*selfTestOut)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error: failed to create --self-test-out: %v\n", err)
+					os.Exit(2)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if format == "json" {
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					fmt.Fprintf(os.Stderr, "error: failed to encode self-test JSON report: %v\n", err)
+					os.Exit(2)
+				}
+			} else {
+				var suites cliJUnitTestSuites
+				for _, r := range results {
+					suites.Suites = append(suites.Suites, toJUnitSuite(r))
+				}
+				fmt.Fprint(w, xml.Header)
+				out, err := xml.MarshalIndent(suites, "", "  ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error: failed to encode self-test JUnit report: %v\n", err)
+					os.Exit(2)
+				}
+				w.Write(out)
+				fmt.Fprintln(w)
+			}
+
+			if totalFailed > 0 {
+				os.Exit(1)
+			}
+			return
 		}
 
+		// Paginate self-test output similar to --all
+		outIsTTY := term.IsTerminal(int(syscall.Stdout))
+		inIsTTY := term.IsTerminal(int(syscall.Stdin))
+		paginate := *pager && outIsTTY && inIsTTY
+		_, height, _ := term.GetSize(int(syscall.Stdout))
+		if height <= 0 {
+			height = 24
+		}
+
+		totalFailed := 0
+
+		// 12 words (no key)
+		fmt.Println(internal.Style("== Self-test: 12 words (no key) ==", internal.Bold))
+		totalFailed += internal.RunSelfTest(active.Words, active.Index, "", policy, *glyphSep, paginate, height, []int{12}, "Self-test (12-word sets)")
+
+		// 12 words (with key; crypto-random)
+		fmt.Println(internal.Style("== Self-test: 12 words (with key) ==", internal.Bold))
+		totalFailed += internal.RunSelfTest(active.Words, active.Index, effKey1, policy, *glyphSep, paginate, height, []int{12}, "Self-test (12-word sets)")
+
+		// 24 words (no key)
+		fmt.Println(internal.Style("== Self-test: 24 words (no key) ==", internal.Bold))
+		totalFailed += internal.RunSelfTest(active.Words, active.Index, "", policy, *glyphSep, paginate, height, []int{24}, "Self-test (24-word sets)")
+
+		// 24 words (with key; crypto-random)
+		fmt.Println(internal.Style("== Self-test: 24 words (with key) ==", internal.Bold))
+		totalFailed += internal.RunSelfTest(active.Words, active.Index, effKey2, policy, *glyphSep, paginate, height, []int{24}, "Self-test (24-word sets)")
+
+		// Shamir split/combine (12-word phrase, 2-of-3)
+		fmt.Println(internal.Style("== Self-test: split/combine (2-of-3) ==", internal.Bold))
+		totalFailed += selfTestSplitCombine()
+
+		// Multi-language wordlist round-trip
+		fmt.Println(internal.Style("== Self-test: wordlist languages ==", internal.Bold))
+		totalFailed += selfTestLanguages()
+
 		if totalFailed > 0 {
 			os.Exit(1)
 		}
@@ -496,7 +1166,7 @@ func main() {
 			}
 			effTabKey = string(effSeed[:])
 		}
-		p, _ := internal.Derive(len(active.Words), effTabKey)
+		p, _ := internal.DeriveWithPolicy(len(active.Words), effTabKey, policy)
 		outIsTTY := term.IsTerminal(int(syscall.Stdout))
 		inIsTTY := term.IsTerminal(int(syscall.Stdin))
 		paginate := *pager && outIsTTY && inIsTTY
@@ -544,6 +1214,9 @@ func main() {
 	}
 
 	tokens := flag.Args()
+	if *qrCombine {
+		tokens = combinedArgs
+	}
 	if len(tokens) == 0 {
 		if *prompt && term.IsTerminal(int(syscall.Stdin)) {
 			// Interactive entry: ask for key first (via --prompt), then the seed/glyphs
@@ -578,6 +1251,98 @@ func main() {
 			os.Exit(0)
 		}
 	}
+	tokens = internal.SplitMnemonicTokens(tokens)
+
+	if autoDetectList {
+		lang, err := internal.DetectLanguage(tokens)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --list=auto: %v\n", err)
+			os.Exit(2)
+		}
+		wl, err := wordListFromLanguage(lang)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --list=auto: %v\n", err)
+			os.Exit(2)
+		}
+		active = wl
+	}
+
+	if strings.TrimSpace(*split) != "" {
+		threshold, total, err := parseMOfN(*split)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid --split: %v\n", err)
+			os.Exit(2)
+		}
+		shares, err := shamir.SplitMnemonic(tokens, threshold, total)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Println(internal.Style(fmt.Sprintf("%d-of-%d shares:", threshold, total), internal.Bold, internal.Blue))
+		for i, s := range shares {
+			fmt.Printf("  Share %d: %s\n", i+1, s)
+		}
+		return
+	}
+
+	if *combine {
+		words, err := shamir.CombineMnemonic(tokens)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Println(internal.Style("Recovered phrase:", internal.Bold, internal.Purple))
+		fmt.Println(strings.Join(words, " "))
+		return
+	}
+
+	// An encrypted glyph blob arrives as a single opaque token; handle it before
+	// glyph-shape detection, which would otherwise reject it outright.
+	if len(tokens) == 1 && internal.IsEncryptedGlyphBlob(tokens[0]) {
+		var gpgPriv io.Reader
+		if strings.TrimSpace(*identityGPG) != "" {
+			f, err := os.Open(*identityGPG)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: failed to open --identity-gpg: %v\n", err)
+				os.Exit(2)
+			}
+			defer f.Close()
+			gpgPriv = f
+		}
+		// The encrypted blob hides its word count until after decrypt+decode, so
+		// the key strength requirement can't be picked up front: derive the seed
+		// without enforcing a guessed context, decode, then enforce against the
+		// actual recovered word count before trusting the result.
+		effKey := keyStr
+		if strings.TrimSpace(keyStr) != "" {
+			effSeed, errK := internal.EffectiveKeyMaterial(keyStr, policy)
+			if errK != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", errK)
+				os.Exit(2)
+			}
+			effKey = string(effSeed[:])
+		}
+		decoded, err := internal.DecodeGlyphTokenEncrypted(tokens[0], active.Words, effKey, policy, *identity, gpgPriv, []byte(*gpgPassphrase))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		if strings.TrimSpace(keyStr) != "" {
+			if errK := internal.EnforceOrError(keyStr, internal.MinBitsForContext(len(decoded)), policy); errK != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", errK)
+				os.Exit(2)
+			}
+		}
+		if *phraseOnly {
+			fmt.Println(strings.Join(decoded, " "))
+			return
+		}
+		fmt.Println(internal.Banner(version))
+		fmt.Println()
+		fmt.Println(internal.Style("Phrase:", internal.Bold, internal.Purple))
+		fmt.Println(strings.Join(decoded, " "))
+		return
+	}
 
 	// Normalize glyph tokens and detect glyph input
 	normTokens := make([]string, len(tokens))
@@ -604,6 +1369,28 @@ func main() {
 
 	if isGlyph {
 
+		if strings.TrimSpace(*verifySig) != "" {
+			if strings.TrimSpace(keyStr) == "" {
+				fmt.Fprintln(os.Stderr, "error: --verify-sig requires --key or --prompt")
+				os.Exit(2)
+			}
+			sig, errHex := hex.DecodeString(strings.TrimSpace(*verifySig))
+			if errHex != nil {
+				fmt.Fprintf(os.Stderr, "error: invalid --verify-sig: %v\n", errHex)
+				os.Exit(2)
+			}
+			minBits := internal.MinBitsForContext(len(normTokens))
+			_, pub, errK := internal.DeriveSigningKey(keyStr, minBits, policy)
+			if errK != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", errK)
+				os.Exit(2)
+			}
+			if err := internal.VerifyGlyphs(normTokens, sig, pub); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(2)
+			}
+		}
+
 		// Decode all tokens first (batch)
 		effKey := keyStr
 		if strings.TrimSpace(keyStr) != "" {
@@ -615,7 +1402,7 @@ func main() {
 			}
 			effKey = string(effSeed[:])
 		}
-		decoded, err := internal.DecodeGlyphTokens(normTokens, active.Words, effKey)
+		decoded, err := internal.DecodeGlyphTokensWithPolicy(normTokens, active.Words, effKey, policy)
 		if err != nil {
 			// Sanitize detailed decode errors to avoid exposing sensitive input
 			fmt.Fprintln(os.Stderr, "error: invalid glyph input")
@@ -715,6 +1502,38 @@ func main() {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(2)
 	}
+	// A recipient means the glyph stream must never hit the terminal in
+	// plaintext: re-run the encode under wraps and print only the armored blob.
+	if strings.TrimSpace(*recipient) != "" || strings.TrimSpace(*recipientGPG) != "" {
+		var gpgPub io.Reader
+		if strings.TrimSpace(*gpgPubring) != "" {
+			f, err := os.Open(*gpgPubring)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: failed to open --gpg-pubring: %v\n", err)
+				os.Exit(2)
+			}
+			defer f.Close()
+			gpgPub = f
+		}
+		effKey := keyStr
+		if strings.TrimSpace(keyStr) != "" {
+			minBits := internal.MinBitsForContext(len(tokens))
+			effSeed, errK := internal.MustEffectiveKeyMaterial(keyStr, minBits, policy)
+			if errK != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", errK)
+				os.Exit(2)
+			}
+			effKey = string(effSeed[:])
+		}
+		blob, err := internal.EncodeWordsEncrypted(tokens, active.Index, active.Words, effKey, policy, *recipient, *recipientGPG, gpgPub)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Println(blob)
+		return
+	}
+
 	for i := range glyphs {
 		glyphs[i] = internal.InsertSep(glyphs[i], *glyphSep)
 	}
@@ -744,11 +1563,38 @@ func main() {
 	} else {
 		fmt.Println(strings.Join(glyphs, *sep))
 	}
+	if *printKDFHeader {
+		if tok, err := internal.KDFHeaderToken(policy); err == nil {
+			fmt.Println()
+			fmt.Printf("%s %s\n", internal.Style("KDF header:", internal.Bold, internal.Gray), tok)
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: failed to build KDF header: %v\n", err)
+		}
+	}
+	if *signOut {
+		if strings.TrimSpace(keyStr) == "" {
+			fmt.Fprintln(os.Stderr, "warning: --sign requires --key or --prompt; skipping signature")
+		} else {
+			minBits := internal.MinBitsForContext(len(tokens))
+			priv, _, err := internal.DeriveSigningKey(keyStr, minBits, policy)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to derive signing key: %v\n", err)
+			} else {
+				sig, err := internal.SignGlyphs(glyphs, *glyphSep, priv)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: failed to sign glyphs: %v\n", err)
+				} else {
+					fmt.Println()
+					fmt.Printf("%s %s\n", internal.Style("Signature:", internal.Bold, internal.Gray), hex.EncodeToString(sig))
+				}
+			}
+		}
+	}
 	if !*noQR {
 		outIsTTY := term.IsTerminal(int(syscall.Stdout))
 		inIsTTY := term.IsTerminal(int(syscall.Stdin))
 		show := true
-		if outIsTTY && inIsTTY {
+		if outIsTTY && inIsTTY && strings.TrimSpace(*qrOut) == "" {
 			fmt.Fprint(os.Stdout, "\nShow QR Code [Y/n]: ")
 			reader := bufio.NewReader(os.Stdin)
 			ans, _ := reader.ReadString('\n')
@@ -760,35 +1606,232 @@ func main() {
 		if show {
 			fmt.Println()
 			payload := strings.Join(glyphs, " ")
-			if code, err := qr.Encode(payload, qr.M); err == nil {
-				size := code.Size
-				for y := 0; y < size; y += 2 {
-					var line strings.Builder
-					for x := 0; x < size; x++ {
-						top := code.Black(x, y)
-						bottom := false
-						if y+1 < size {
-							bottom = code.Black(x, y+1)
-						}
-						switch {
-						case top && bottom:
-							line.WriteRune('█')
-						case top && !bottom:
-							line.WriteRune('▀')
-						case !top && bottom:
-							line.WriteRune('▄')
-						default:
-							line.WriteByte(' ')
-						}
-					}
-					fmt.Fprintln(os.Stdout, line.String())
+			level, err := qrLevelFromFlag(*qrECC)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v; using M\n", err)
+				level = qr.M
+			}
+			frames, err := qrMultiPartFrames(payload, *qrSplit)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v; falling back to a single QR code\n", err)
+				frames = []string{payload}
+			}
+			if strings.TrimSpace(*qrOut) != "" {
+				if err := writeQRCodesPNG(frames, level, *qrOut); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: failed to write --qr-out: %v\n", err)
 				}
 			} else {
-				fmt.Fprintln(os.Stdout, "(QR generation failed)")
+				printQRCodesTerminal(frames, level)
 			}
 		}
 	}
 	fmt.Println()
 }
 
+// qrLevelFromFlag parses --qr-ecc's L/M/Q/H (case-insensitive) into the
+// rsc.io/qr error-correction level used by qr.Encode.
+func qrLevelFromFlag(s string) (qr.Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "L":
+		return qr.L, nil
+	case "M", "":
+		return qr.M, nil
+	case "Q":
+		return qr.Q, nil
+	case "H":
+		return qr.H, nil
+	default:
+		return qr.M, fmt.Errorf("invalid --qr-ecc=%q (want L, M, Q, or H)", s)
+	}
+}
+
+// qrCombineFrames reverses qrMultiPartFrames: given the raw scanned text of
+// every frame (in any order), it strips each "GLYPHRIOT-MP k/n parity"
+// header, checks that all k in 1..n are present exactly once and every frame
+// agrees on n and parity, then reassembles the original payload and verifies
+// the parity byte against it. This is glyphriot's own reassembly helper, not
+// ISO/IEC 18004 Structured Append, so it only round-trips frames produced by
+// --qr-split; a generic phone scanner still can't reassemble them on its own.
+func qrCombineFrames(raw []string) (string, error) {
+	if len(raw) == 0 {
+		return "", fmt.Errorf("no frames provided")
+	}
+	type part struct {
+		k, n   int
+		parity byte
+		body   string
+	}
+	parts := make([]part, 0, len(raw))
+	for _, f := range raw {
+		header, body, ok := strings.Cut(strings.TrimRight(f, "\r\n"), "\n")
+		if !ok {
+			return "", fmt.Errorf("frame missing GLYPHRIOT-MP header")
+		}
+		var k, n int
+		var parityHex string
+		if _, err := fmt.Sscanf(header, "GLYPHRIOT-MP %d/%d %s", &k, &n, &parityHex); err != nil {
+			return "", fmt.Errorf("malformed frame header %q: %w", header, err)
+		}
+		parityByte, err := hex.DecodeString(parityHex)
+		if err != nil || len(parityByte) != 1 {
+			return "", fmt.Errorf("malformed frame header %q: bad parity", header)
+		}
+		parts = append(parts, part{k: k, n: n, parity: parityByte[0], body: body})
+	}
+
+	n := parts[0].n
+	parity := parts[0].parity
+	if len(parts) != n {
+		return "", fmt.Errorf("have %d frame(s), header(s) say %d", len(parts), n)
+	}
+	ordered := make([]string, n)
+	seen := make([]bool, n+1)
+	for _, p := range parts {
+		if p.n != n || p.parity != parity {
+			return "", fmt.Errorf("frames disagree on total count or parity; did they come from the same --qr-split run?")
+		}
+		if p.k < 1 || p.k > n || seen[p.k] {
+			return "", fmt.Errorf("duplicate or out-of-range frame index %d/%d", p.k, n)
+		}
+		seen[p.k] = true
+		ordered[p.k-1] = p.body
+	}
+
+	payload := strings.Join(ordered, "")
+	var gotParity byte
+	for _, b := range []byte(payload) {
+		gotParity ^= b
+	}
+	if gotParity != parity {
+		return "", fmt.Errorf("reassembled payload failed parity check")
+	}
+	return payload, nil
+}
+
+// qrMultiPartFrames splits payload into n chunks (by rune, so a multi-byte
+// glyph is never cut in half) and prefixes each with a small text header
+// carrying a sequence index, total count, and a parity byte (the XOR of
+// every byte in the original, unsplit payload).
+//
+// This is NOT ISO/IEC 18004 Structured Append: that's a mode segment written
+// into the QR symbol itself, which rsc.io/qr has no API to produce, and
+// which a standards-compliant reader reassembles on its own. This is a
+// glyphriot-specific convention instead — the header rides inside the
+// ordinary payload bytes, so any QR reader can still scan each symbol, but
+// only this tool (via --qr-combine, see qrCombineFrames) knows how to
+// reassemble them using the "GLYPHRIOT-MP k/n parity" header. n<=1 returns
+// payload unframed, the existing single-QR behavior.
+func qrMultiPartFrames(payload string, n int) ([]string, error) {
+	if n <= 1 {
+		return []string{payload}, nil
+	}
+	runes := []rune(payload)
+	if n > len(runes) {
+		return nil, fmt.Errorf("--qr-split=%d exceeds payload length (%d runes)", n, len(runes))
+	}
+
+	var parity byte
+	for _, b := range []byte(payload) {
+		parity ^= b
+	}
+
+	chunkSize := (len(runes) + n - 1) / n
+	frames := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		start := i * chunkSize
+		if start >= len(runes) {
+			break
+		}
+		end := start + chunkSize
+		if end > len(runes) {
+			end = len(runes)
+		}
+		header := fmt.Sprintf("GLYPHRIOT-MP %d/%d %02x\n", i+1, n, parity)
+		frames = append(frames, header+string(runes[start:end]))
+	}
+	if len(frames) != n {
+		return nil, fmt.Errorf("--qr-split=%d produced %d non-empty chunk(s); reduce N", n, len(frames))
+	}
+	return frames, nil
+}
+
+// printQRCodesTerminal prints one or more QR codes to stdout using the
+// existing half-block renderer, captioned "[k/N]" when there's more than one
+// (glyphriot's own multi-part framing, not standard Structured Append; see
+// qrMultiPartFrames).
+func printQRCodesTerminal(frames []string, level qr.Level) {
+	for i, frame := range frames {
+		code, err := qr.Encode(frame, level)
+		if err != nil {
+			fmt.Fprintln(os.Stdout, "(QR generation failed)")
+			continue
+		}
+		if len(frames) > 1 {
+			fmt.Printf("[%d/%d]\n", i+1, len(frames))
+		}
+		printQRTerminal(code)
+		fmt.Println()
+	}
+}
+
+// printQRTerminal renders code to stdout using two vertically-stacked pixels
+// per character cell (▀▄█) so a QR code fits in about half the terminal rows
+// a literal 1:1 pixel mapping would need.
+func printQRTerminal(code *qr.Code) {
+	size := code.Size
+	for y := 0; y < size; y += 2 {
+		var line strings.Builder
+		for x := 0; x < size; x++ {
+			top := code.Black(x, y)
+			bottom := false
+			if y+1 < size {
+				bottom = code.Black(x, y+1)
+			}
+			switch {
+			case top && bottom:
+				line.WriteRune('█')
+			case top && !bottom:
+				line.WriteRune('▀')
+			case !top && bottom:
+				line.WriteRune('▄')
+			default:
+				line.WriteByte(' ')
+			}
+		}
+		fmt.Fprintln(os.Stdout, line.String())
+	}
+}
+
+// writeQRCodesPNG writes each frame as a PNG to basePath: basePath itself
+// when there's one frame, or "base-1.png".."base-N.png" (preserving
+// basePath's extension) when --qr-split produced more than one.
+func writeQRCodesPNG(frames []string, level qr.Level, basePath string) error {
+	for i, frame := range frames {
+		code, err := qr.Encode(frame, level)
+		if err != nil {
+			return fmt.Errorf("encoding QR %d/%d: %w", i+1, len(frames), err)
+		}
+		path := basePath
+		if len(frames) > 1 {
+			ext := filepath.Ext(basePath)
+			base := strings.TrimSuffix(basePath, ext)
+			path = fmt.Sprintf("%s-%d%s", base, i+1, ext)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", path, err)
+		}
+		err = png.Encode(f, code.Image())
+		closeErr := f.Close()
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		if closeErr != nil {
+			return fmt.Errorf("closing %s: %w", path, closeErr)
+		}
+		fmt.Fprintf(os.Stdout, "wrote %s\n", path)
+	}
+	return nil
+}
+
 // Helpers